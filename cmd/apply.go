@@ -0,0 +1,163 @@
+/*
+ * Copyright (c) 2023 The nebula-contrib Authors.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *     http://www.apache.org/licenses/LICENSE-2.
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/vesoft-inc/nebula-operator/apis/apps/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/nebula-contrib/ngctl/pkg/diff"
+	"github.com/nebula-contrib/ngctl/pkg/logger"
+	"github.com/nebula-contrib/ngctl/pkg/manifest"
+	"github.com/nebula-contrib/ngctl/pkg/util"
+)
+
+const fieldManager = util.FieldManager
+
+func applyCmd() *cobra.Command {
+	var (
+		file  string
+		force bool
+	)
+	cmd := &cobra.Command{
+		Use:   "apply",
+		Short: "apply a nebula graph cluster manifest",
+		Long:  "apply a nebula graph cluster manifest with server-side apply and show the changes.",
+		Example: `  # preview the changes of cluster.yaml without persisting them
+  ngctl apply -f cluster.yaml --dry-run=server
+  # print the manifest with the namespace filled in without contacting the server
+  ngctl apply -f cluster.yaml --dry-run=client
+  # apply cluster.yaml
+  ngctl apply -f cluster.yaml
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if file == "" {
+				return errors.New("please specify the manifest file by -f")
+			}
+			return apply(cmd.Context(), file, namespaceOrDefault(), dryRun, force)
+		},
+	}
+	supportDryRun(cmd)
+	cmd.PersistentFlags().StringVarP(&file, "file", "f", "", "path of the nebula graph cluster manifest, - means stdin")
+	cmd.PersistentFlags().BoolVar(&force, "force-conflicts", false, "if set, take the ownership of the fields managed by others")
+	return cmd
+}
+
+func apply(ctx context.Context, file, namespace, dryRun string, force bool) error {
+	content, err := manifest.Read(file, stdin)
+	if err != nil {
+		return err
+	}
+	objects, err := manifest.DecodeClusters(content)
+	if err != nil {
+		return err
+	}
+
+	for _, object := range objects {
+		if object.GetNamespace() == "" {
+			object.SetNamespace(namespace)
+		}
+		if _, err = manifest.ToCluster(object); err != nil {
+			return fmt.Errorf("invalid nebula graph cluster %s: %w", object.GetName(), err)
+		}
+	}
+	if dryRun == dryRunClient {
+		runtimeObjects := make([]runtime.Object, 0, len(objects))
+		for _, object := range objects {
+			runtimeObjects = append(runtimeObjects, object)
+		}
+		return printManifests(stdout, runtimeObjects...)
+	}
+
+	client, err := newDynamicClient()
+	if err != nil {
+		return err
+	}
+	for _, object := range objects {
+		if err = applyCluster(ctx, client, object, dryRun == dryRunServer, force); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applyCluster(ctx context.Context, client dynamic.Interface, object *unstructured.Unstructured, dryRun, force bool) error {
+	name, namespace := object.GetName(), object.GetNamespace()
+
+	var live *unstructured.Unstructured
+	nc, err := getCluster(ctx, client, name, namespace)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	if err == nil {
+		if live, err = clusterObject(nc); err != nil {
+			return err
+		}
+	}
+
+	// apply with dry run first to preview the merged result computed by the server
+	resourceInterface := client.Resource(v1alpha1.GroupVersion.WithResource("nebulaclusters")).Namespace(namespace)
+	options := metav1.ApplyOptions{FieldManager: fieldManager, Force: force, DryRun: []string{metav1.DryRunAll}}
+	result, err := resourceInterface.Apply(ctx, name, object, options)
+	if err != nil {
+		return err
+	}
+	// convert the result the same way as the live object so that only the real changes are shown
+	merged, err := manifest.ToCluster(result)
+	if err != nil {
+		return err
+	}
+	if result, err = clusterObject(merged); err != nil {
+		return err
+	}
+	changes := diff.Compare(diff.Normalize(live), diff.Normalize(result))
+	if len(changes) == 0 {
+		logger.Infof("nebula graph cluster %s is unchanged", name)
+		return nil
+	}
+	logger.Infof("changes of nebula graph cluster %s:", name)
+	diff.Print(stdout, changes)
+
+	if dryRun {
+		logger.Infof("nebula graph cluster %s is validated (server dry run)", name)
+		return nil
+	}
+	options.DryRun = nil
+	if _, err = resourceInterface.Apply(ctx, name, object, options); err != nil {
+		return err
+	}
+	if live == nil {
+		logger.Infof("nebula graph cluster %s is created", name)
+	} else {
+		logger.Infof("nebula graph cluster %s is configured", name)
+	}
+	return nil
+}
+
+func clusterObject(nc *v1alpha1.NebulaCluster) (*unstructured.Unstructured, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(nc)
+	if err != nil {
+		return nil, err
+	}
+	return &unstructured.Unstructured{Object: content}, nil
+}
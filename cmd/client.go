@@ -0,0 +1,178 @@
+/*
+ * Copyright (c) 2023 The nebula-contrib Authors.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *     http://www.apache.org/licenses/LICENSE-2.
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/nebula-contrib/ngctl/pkg/config"
+	"github.com/nebula-contrib/ngctl/pkg/factory"
+	"github.com/nebula-contrib/ngctl/pkg/list"
+	"github.com/nebula-contrib/ngctl/pkg/logger"
+	"github.com/nebula-contrib/ngctl/pkg/util"
+)
+
+const (
+	EnvCluster   = "NGCTL_CLUSTER"
+	EnvNamespace = "NGCTL_NAMESPACE"
+)
+
+// boundContext is the ngctl context loaded by the current command, the kubernetes clients are bound to its
+// kubeconfig and kubernetes context unless --kubeconfig or --context is set
+var boundContext *config.Context
+
+var (
+	// injectedFactory replaces the factory created from the flags if it is set
+	injectedFactory factory.Factory
+	// currentFactory is the factory of the current invocation
+	currentFactory factory.Factory
+)
+
+// kubeOptions returns the options to load the kubernetes config
+func kubeOptions() *util.KubeOptions {
+	options := &util.KubeOptions{
+		KubeConfig:        kubeConfig,
+		Context:           kubeContext,
+		User:              kubeUser,
+		Impersonate:       impersonate,
+		ImpersonateGroups: impersonateGroups,
+		QPS:               qps,
+		Burst:             burst,
+		Timeout:           requestTimeout,
+	}
+	if boundContext != nil {
+		if options.KubeConfig == "" {
+			options.KubeConfig = boundContext.KubeConfig
+		}
+		if options.Context == "" {
+			options.Context = boundContext.KubeContext
+		}
+	}
+	return options
+}
+
+// clientFactory returns the factory of the kubernetes clients, the rest config is loaded once per invocation
+func clientFactory() factory.Factory {
+	if injectedFactory != nil {
+		return injectedFactory
+	}
+	if currentFactory == nil {
+		currentFactory = factory.New(kubeOptions())
+	}
+	return currentFactory
+}
+
+// SetFactory injects the factory of the kubernetes clients, such as a fake one in tests
+func SetFactory(f factory.Factory) {
+	injectedFactory = f
+}
+
+func newRESTConfig() (*rest.Config, error) {
+	config, err := clientFactory().RESTConfig()
+	return config, contextError(err)
+}
+
+func newClientSet() (kubernetes.Interface, error) {
+	clientSet, err := clientFactory().ClientSet()
+	return clientSet, contextError(err)
+}
+
+func newDynamicClient() (dynamic.Interface, error) {
+	client, err := clientFactory().DynamicClient()
+	return client, contextError(err)
+}
+
+// contextError explains the error of loading the kubernetes config of the bound context
+func contextError(err error) error {
+	if err == nil || boundContext == nil || injectedFactory != nil {
+		return err
+	}
+	if options := kubeOptions(); options.Context == boundContext.KubeContext {
+		return fmt.Errorf("kubernetes context %s of context %s: %w", options.Context, boundContext.Name, err)
+	}
+	return err
+}
+
+// selectCluster returns the name and namespace of the nebula graph cluster to operate on, the cluster is selected by
+// -c/--cluster, NGCTL_CLUSTER or the context in use in order, and the namespace by -n/--namespace, NGCTL_NAMESPACE,
+// the context in use or the default namespace in order
+func selectCluster(ctx context.Context) (string, string, error) {
+	if name := flagOrEnv(clusterName, EnvCluster); name != "" {
+		return name, namespaceOrDefault(), nil
+	}
+
+	conf, err := config.LoadConfig()
+	if errors.Is(err, config.ErrNoContext) {
+		return "", "", noClusterError(ctx)
+	}
+	if err != nil {
+		return "", "", err
+	}
+	if kubeContext != "" && conf.KubeContext != "" && kubeContext != conf.KubeContext {
+		logger.Warningf("context %s belongs to kubernetes context %s, but %s is used", conf.Name, conf.KubeContext, kubeContext)
+	}
+	boundContext = conf
+	// the clients are created again with the kubernetes context of conf
+	currentFactory = nil
+	namespace := conf.Namespace
+	if ns := flagOrEnv(clusterNamespace, EnvNamespace); ns != "" {
+		namespace = ns
+	}
+	return conf.Cluster, namespace, nil
+}
+
+// namespaceOrDefault returns the namespace set by -n/--namespace or NGCTL_NAMESPACE, or the default namespace
+func namespaceOrDefault() string {
+	if namespace := flagOrEnv(clusterNamespace, EnvNamespace); namespace != "" {
+		return namespace
+	}
+	return metav1.NamespaceDefault
+}
+
+func flagOrEnv(value, env string) string {
+	if value != "" {
+		return value
+	}
+	return os.Getenv(env)
+}
+
+// noClusterError returns the error that no nebula graph cluster is selected with the available ones
+func noClusterError(ctx context.Context) error {
+	const message = "no nebula graph cluster is selected, please specify it by -c/--cluster, " + EnvCluster + " or ngctl use"
+	client, err := newDynamicClient()
+	if err != nil {
+		return errors.New(message)
+	}
+	clusters, err := list.Clusters(ctx, client, true, "")
+	if err != nil {
+		return errors.New(message)
+	}
+	if len(clusters) == 0 {
+		return errors.New(message + ", no nebula graph cluster is found in kubernetes")
+	}
+	names := make([]string, 0, len(clusters))
+	for _, cluster := range clusters {
+		names = append(names, cluster.Namespace+"/"+cluster.Name)
+	}
+	return fmt.Errorf("%s, the available clusters are: %s", message, strings.Join(names, ", "))
+}
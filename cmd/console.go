@@ -16,21 +16,26 @@ package cmd
 import (
 	"context"
 	"errors"
-	"log"
+	"fmt"
+	"time"
 
 	"github.com/spf13/cobra"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/tools/clientcmd"
 
-	"github.com/nebula-contrib/ngctl/pkg/config"
+	"github.com/nebula-contrib/ngctl/pkg/cluster"
 	"github.com/nebula-contrib/ngctl/pkg/console"
+	"github.com/nebula-contrib/ngctl/pkg/logger"
 	"github.com/nebula-contrib/ngctl/pkg/util"
 )
 
 const consoleLabel = "ngctl/nebula-console"
 
+// cleanupTimeout is the timeout of the requests made after the context of the command is done, such as removing
+// the temporary resources
+const cleanupTimeout = 30 * time.Second
+
 func consoleCmd() *cobra.Command {
 	var (
 		image string
@@ -40,17 +45,24 @@ func consoleCmd() *cobra.Command {
 		Use:   "console",
 		Short: "nebula console client for nebula graph ",
 		Long:  "nebula console client for nebula graph.",
+		Example: `  # connect to the nebula graph cluster of the current context
+  ngctl console -u root -p nebula
+  # print the console pod and its config map without creating them
+  ngctl console --dry-run=client
+`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return run(option, image)
+			return run(cmd.Context(), option, image)
 		},
 	}
+	supportDryRun(cmd)
 
 	cmd.PersistentFlags().StringVar(&image, "image", "vesoft/nebula-console:v3.5", "image of the nebula graph console")
-	cmd.PersistentFlags().StringVarP(&option.PodName, "pod_name", "n", "nebula-console", "set the name of the console pod. ")
+	cmd.PersistentFlags().StringVarP(&option.PodName, "pod_name", "", "nebula-console", "set the name of the console pod. ")
 
-	cmd.PersistentFlags().StringVarP(&option.Username, "user", "u", "root", "set the username of the NebulaGraph account. ")
+	// the username is not --user, which is the global flag of the kubeconfig user
+	cmd.PersistentFlags().StringVarP(&option.Username, "graph-user", "u", "root", "set the username of the NebulaGraph account. ")
 	cmd.PersistentFlags().StringVarP(&option.Password, "password", "p", "", "set the password of the NebulaGraph account. ")
-	cmd.PersistentFlags().Int32VarP(&option.Timeout, "timeout", "t", 120, "set the connection timeout in milliseconds. ")
+	cmd.PersistentFlags().Int32VarP(&option.Timeout, "connect-timeout", "t", 120, "set the connection timeout in milliseconds. ")
 	cmd.PersistentFlags().StringVarP(&option.Eval, "eval", "e", "", "set the nGQL statement in string type. ")
 	cmd.PersistentFlags().StringVarP(&option.File, "file", "f", "", "set the path of the file that stores nGQL statements. ")
 	cmd.PersistentFlags().BoolVarP(&option.EnableSsl, "enable_ssl", "", false, "connect to NebulaGraph using SSL encryption and two-way authentication. ")
@@ -61,22 +73,23 @@ func consoleCmd() *cobra.Command {
 	return cmd
 }
 
-func run(option console.Option, image string) error {
-	ngctlConfig, err := config.LoadConfig()
+func run(ctx context.Context, option console.Option, image string) error {
+	name, namespace, err := selectCluster(ctx)
 	if err != nil {
 		return err
 	}
-	option.Name, option.Namespace = ngctlConfig.Name, ngctlConfig.Namespace
-
-	ctx := context.Background()
+	option.Name, option.Namespace = name, namespace
+	if dryRun != dryRunNone {
+		return dryRunConsole(ctx, &option, image)
+	}
 
-	conf, err := clientcmd.BuildConfigFromFlags("", kubeConfig)
+	conf, err := newRESTConfig()
 	if err != nil {
-		return nil
+		return err
 	}
-	clientSet, err := kubernetes.NewForConfig(conf)
+	clientSet, err := newClientSet()
 	if err != nil {
-		return nil
+		return err
 	}
 
 	err = initConsole(ctx, clientSet, &option, image)
@@ -92,24 +105,36 @@ func run(option console.Option, image string) error {
 	return nil
 }
 
-func initConsole(ctx context.Context, clientSet *kubernetes.Clientset, option *console.Option, image string) error {
+// initConsole creates the console pod and waits until it is running, the console pod and its config map are
+// removed if they are created by this invocation and the pod is not running
+func initConsole(ctx context.Context, clientSet kubernetes.Interface, option *console.Option, image string) (err error) {
 	podName, namespace := option.PodName, option.Namespace
-	if err := loadConfig(ctx, clientSet, option, podName, namespace); err != nil {
+	pods := clientSet.CoreV1().Pods(namespace)
+	status, err := util.Check[*corev1.Pod](ctx, pods, podName, consoleLabel)
+	if err != nil {
 		return err
 	}
-	pods := clientSet.CoreV1().Pods(namespace)
-	status := util.Check[corev1.Pod](ctx, pods, podName, consoleLabel)
 	if status == util.StatusConflicted {
 		return errors.New("console pod is conflicted with already exist pod, please check")
-	} else if status == util.StatusAlready {
-		log.Printf("console pod is already ready, skip init pod")
+	}
+	if err = loadConfig(ctx, clientSet, option, podName, namespace); err != nil {
+		return err
+	}
+	if status == util.StatusAlready {
+		logger.V(1).Infof("console pod is already ready, skip init pod")
 	} else {
+		// the config map is removed as well if the pod fails to be created
+		defer func() {
+			if err != nil {
+				removeConsole(clientSet, podName, namespace)
+			}
+		}()
 		// create pod
 		labels := map[string]string{
 			consoleLabel: podName,
 		}
 		pod := console.CratePod(podName, namespace, labels, image, option)
-		_, err := clientSet.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{})
+		_, err = clientSet.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{})
 		if err != nil {
 			return err
 		}
@@ -122,21 +147,74 @@ func initConsole(ctx context.Context, clientSet *kubernetes.Clientset, option *c
 	if err != nil {
 		return err
 	}
-	for event := range watch.ResultChan() {
-		pod, ok := event.Object.(*corev1.Pod)
-		if !ok {
-			log.Printf("convert event object to pod failed")
-			continue
-		}
-		if pod.Status.Phase == corev1.PodRunning {
-			log.Printf("console pod is ready")
-			break
+	defer watch.Stop()
+	var last string
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("wait for console pod %s: %w", podName, ctx.Err())
+		case event, ok := <-watch.ResultChan():
+			if !ok {
+				return fmt.Errorf("watch of console pod %s is closed", podName)
+			}
+			pod, ok := event.Object.(*corev1.Pod)
+			if !ok {
+				logger.Warningf("convert event object to pod failed")
+				continue
+			}
+			if pod.Status.Phase == corev1.PodRunning {
+				logger.Infof("console pod is ready")
+				return nil
+			}
+			if issue := cluster.PodIssue(pod); issue != "" && issue != last {
+				logger.Infof("console pod is not ready, %s", issue)
+				last = issue
+			}
 		}
 	}
-	return nil
 }
 
-func loadConfig(ctx context.Context, clientSet *kubernetes.Clientset, option *console.Option, name, namespace string) error {
+// dryRunConsole prints the console pod and its config map, or submits them with server dry run, the console is
+// not started in both modes
+func dryRunConsole(ctx context.Context, option *console.Option, image string) error {
+	podName, namespace := option.PodName, option.Namespace
+	labels := map[string]string{
+		consoleLabel: podName,
+	}
+	configMap, err := console.CreateConfigMap(podName, namespace, labels, option)
+	if err != nil {
+		return err
+	}
+	pod := console.CratePod(podName, namespace, labels, image, option)
+	if dryRun == dryRunClient {
+		return printManifests(stdout, configMap, pod)
+	}
+
+	clientSet, err := newClientSet()
+	if err != nil {
+		return err
+	}
+	err = util.CreateOrUpdate[*corev1.ConfigMap](ctx, clientSet.CoreV1().ConfigMaps(namespace), configMap, consoleLabel, true)
+	if err != nil {
+		return err
+	}
+	return util.CreateOrUpdate[*corev1.Pod](ctx, clientSet.CoreV1().Pods(namespace), pod, consoleLabel, true)
+}
+
+// removeConsole removes the console pod and its config map, a new context is used since the context of the
+// command may be done
+func removeConsole(clientSet kubernetes.Interface, name, namespace string) {
+	ctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+	defer cancel()
+	if err := util.RemoveResource[*corev1.Pod](ctx, clientSet.CoreV1().Pods(namespace), name, consoleLabel); err != nil {
+		logger.Warningf("remove console pod %s: %v", name, err)
+	}
+	if err := util.RemoveResource[*corev1.ConfigMap](ctx, clientSet.CoreV1().ConfigMaps(namespace), name, consoleLabel); err != nil {
+		logger.Warningf("remove config map %s: %v", name, err)
+	}
+}
+
+func loadConfig(ctx context.Context, clientSet kubernetes.Interface, option *console.Option, name, namespace string) error {
 	configMaps := clientSet.CoreV1().ConfigMaps(namespace)
 	labels := map[string]string{
 		consoleLabel: name,
@@ -145,7 +223,7 @@ func loadConfig(ctx context.Context, clientSet *kubernetes.Clientset, option *co
 	if err != nil {
 		return err
 	}
-	err = util.CreateOrUpdate[corev1.ConfigMap](ctx, configMaps, configMap, name, consoleLabel)
+	err = util.CreateOrUpdate[*corev1.ConfigMap](ctx, configMaps, configMap, consoleLabel, false)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,212 @@
+/*
+ * Copyright (c) 2023 The nebula-contrib Authors.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *     http://www.apache.org/licenses/LICENSE-2.
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+
+	"github.com/nebula-contrib/ngctl/pkg/config"
+	"github.com/nebula-contrib/ngctl/pkg/logger"
+	"github.com/nebula-contrib/ngctl/pkg/printer"
+)
+
+func contextCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "context",
+		Short: "manage the contexts of nebula graph clusters",
+		Long: `manage the contexts of nebula graph clusters saved in ~/.ngctl/config.
+a context is a named nebula graph cluster with its namespace, kubeconfig and kubernetes context, it is created by ngctl use.`,
+		Example: `  # list the contexts
+  ngctl context list
+  # switch to the context prod
+  ngctl context use prod
+  # rename the context nebula to staging
+  ngctl context rename nebula staging
+`,
+	}
+
+	list := cobra.Command{
+		Use:   "list",
+		Short: "list the contexts",
+		Long:  "list the contexts.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return listContexts()
+		},
+	}
+	cmd.AddCommand(&list)
+
+	use := cobra.Command{
+		Use:   "use NAME",
+		Short: "switch to a context",
+		Long:  "switch to a context.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return useContext(args[0])
+		},
+	}
+	cmd.AddCommand(&use)
+
+	rename := cobra.Command{
+		Use:   "rename OLD_NAME NEW_NAME",
+		Short: "rename a context",
+		Long:  "rename a context.",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return renameContext(args[0], args[1])
+		},
+	}
+	cmd.AddCommand(&rename)
+
+	del := cobra.Command{
+		Use:   "delete NAME",
+		Short: "delete a context",
+		Long:  "delete a context, the nebula graph cluster is not affected.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return deleteContext(args[0])
+		},
+	}
+	cmd.AddCommand(&del)
+
+	current := cobra.Command{
+		Use:   "current",
+		Short: "print the name of the current context",
+		Long:  "print the name of the current context.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return currentContext()
+		},
+	}
+	cmd.AddCommand(&current)
+
+	return cmd
+}
+
+// contextList is the json and yaml output of the context list command
+type contextList struct {
+	CurrentContext string           `json:"currentContext"`
+	Items          []config.Context `json:"items"`
+}
+
+func (l *contextList) Tables() []printer.Table {
+	t := printer.Table{
+		Header:      table.Row{"CURRENT", "NAME", "CLUSTER", "NAMESPACE", "KUBE CONTEXT", "KUBECONFIG"},
+		WideColumns: 1,
+	}
+	for _, context := range l.Items {
+		current := ""
+		if context.Name == l.CurrentContext {
+			current = "*"
+		}
+		t.Rows = append(t.Rows, table.Row{current, context.Name, context.Cluster, context.Namespace,
+			context.KubeContext, context.KubeConfig})
+	}
+	return []printer.Table{t}
+}
+
+func (l *contextList) Names() []string {
+	names := make([]string, 0, len(l.Items))
+	for _, context := range l.Items {
+		names = append(names, "context/"+context.Name)
+	}
+	return names
+}
+
+func (l *contextList) Object() interface{} {
+	return l
+}
+
+func (l *contextList) Objects() []interface{} {
+	items := make([]interface{}, 0, len(l.Items))
+	for i := range l.Items {
+		items = append(items, &l.Items[i])
+	}
+	return items
+}
+
+func listContexts() error {
+	if err := printer.Validate(output); err != nil {
+		return err
+	}
+	conf, err := config.Load()
+	if err != nil {
+		return err
+	}
+	result := &contextList{CurrentContext: conf.CurrentContext, Items: conf.Contexts}
+	if result.Items == nil {
+		result.Items = []config.Context{}
+	}
+	return printer.Print(stdout, output, result)
+}
+
+func useContext(name string) error {
+	conf, err := config.Load()
+	if err != nil {
+		return err
+	}
+	context, ok := conf.Context(name)
+	if !ok {
+		return fmt.Errorf("context %s is not found", name)
+	}
+	conf.CurrentContext = name
+	if err = conf.Save(); err != nil {
+		return err
+	}
+	logger.Infof("use nebula graph cluster %s in namespace %s as context %s", context.Cluster, context.Namespace, name)
+	return nil
+}
+
+func renameContext(from, to string) error {
+	conf, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if err = conf.Rename(from, to); err != nil {
+		return err
+	}
+	if err = conf.Save(); err != nil {
+		return err
+	}
+	logger.Infof("context %s is renamed to %s", from, to)
+	return nil
+}
+
+func deleteContext(name string) error {
+	conf, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if err = conf.Delete(name); err != nil {
+		return err
+	}
+	if err = conf.Save(); err != nil {
+		return err
+	}
+	logger.Infof("context %s is deleted", name)
+	return nil
+}
+
+func currentContext() error {
+	conf, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if conf.CurrentContext == "" {
+		return config.ErrNoContext
+	}
+	fmt.Fprintln(stdout, conf.CurrentContext)
+	return nil
+}
@@ -0,0 +1,170 @@
+/*
+ * Copyright (c) 2023 The nebula-contrib Authors.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *     http://www.apache.org/licenses/LICENSE-2.
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/vesoft-inc/nebula-operator/apis/apps/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/nebula-contrib/ngctl/pkg/cluster"
+	"github.com/nebula-contrib/ngctl/pkg/logger"
+)
+
+func createCmd() *cobra.Command {
+	var (
+		preset string
+		wait   bool
+	)
+	option := cluster.Presets["dev"]
+	cmd := &cobra.Command{
+		Use:   "create NAME",
+		Short: "create a nebula graph cluster",
+		Long:  "create a nebula graph cluster from flags or a preset.",
+		Example: `  # create a nebula graph cluster with the dev preset
+  ngctl create nebula
+  # create a nebula graph cluster with the prod preset and 5 storaged replicas
+  ngctl create nebula --preset prod --storaged-replicas 5 --storage-class fast
+  # create a nebula graph cluster and wait until all components are running
+  ngctl create nebula --wait --timeout 10m
+  # print the manifest of the nebula graph cluster without creating it
+  ngctl create nebula --preset prod --dry-run=client
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return errors.New("please specify the name of Nebula Graph cluster")
+			}
+			option.Name, option.Namespace = args[0], namespaceOrDefault()
+			if err := applyPreset(cmd.PersistentFlags(), &option, preset); err != nil {
+				return err
+			}
+			return createCluster(cmd.Context(), &option, wait)
+		},
+	}
+	supportDryRun(cmd)
+	flags := cmd.PersistentFlags()
+	flags.StringVar(&preset, "preset", "dev", fmt.Sprintf("preset of the nebula graph cluster, one of %s", strings.Join(cluster.PresetNames(), "|")))
+	flags.StringVar(&option.Version, "version", option.Version, "version of the nebula graph cluster")
+	flags.Int32Var(&option.GraphdReplicas, "graphd-replicas", option.GraphdReplicas, "replicas of graphd")
+	flags.Int32Var(&option.MetadReplicas, "metad-replicas", option.MetadReplicas, "replicas of metad")
+	flags.Int32Var(&option.StoragedReplicas, "storaged-replicas", option.StoragedReplicas, "replicas of storaged")
+	flags.StringVar(&option.CPURequest, "cpu-request", option.CPURequest, "cpu request of each component")
+	flags.StringVar(&option.MemoryRequest, "memory-request", option.MemoryRequest, "memory request of each component")
+	flags.StringVar(&option.CPULimit, "cpu-limit", option.CPULimit, "cpu limit of each component")
+	flags.StringVar(&option.MemoryLimit, "memory-limit", option.MemoryLimit, "memory limit of each component")
+	flags.StringVar(&option.StorageClass, "storage-class", option.StorageClass, "storage class of the volumes, use the default storage class if empty")
+	flags.StringVar(&option.MetadDataVolume, "metad-data-volume", option.MetadDataVolume, "size of the metad data volume")
+	flags.StringVar(&option.StoragedDataVolume, "storaged-data-volume", option.StoragedDataVolume, "size of the storaged data volume")
+	flags.StringVar(&option.LogVolume, "log-volume", option.LogVolume, "size of the log volume of each component")
+	flags.StringVar(&option.ServiceType, "service-type", option.ServiceType, "service type of graphd, one of ClusterIP|NodePort|LoadBalancer")
+	flags.BoolVar(&wait, "wait", false, "if set, wait until all components are running, --timeout defaults to 10m")
+	return cmd
+}
+
+// applyPreset loads the preset into option and keeps the values of the flags set explicitly
+func applyPreset(flags *pflag.FlagSet, option *cluster.Option, name string) error {
+	preset, ok := cluster.Presets[name]
+	if !ok {
+		return fmt.Errorf("unknown preset %s, available presets: %s", name, strings.Join(cluster.PresetNames(), ", "))
+	}
+	changed := map[string]string{}
+	flags.Visit(func(flag *pflag.Flag) {
+		changed[flag.Name] = flag.Value.String()
+	})
+	preset.Name, preset.Namespace = option.Name, option.Namespace
+	*option = preset
+	for name, value := range changed {
+		if err := flags.Set(name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func createCluster(ctx context.Context, option *cluster.Option, wait bool) error {
+	nc, err := cluster.Build(option)
+	if err != nil {
+		return err
+	}
+	object, err := runtime.DefaultUnstructuredConverter.ToUnstructured(nc)
+	if err != nil {
+		return err
+	}
+	if dryRun == dryRunClient {
+		return printManifests(stdout, &unstructured.Unstructured{Object: object})
+	}
+
+	client, err := newDynamicClient()
+	if err != nil {
+		return err
+	}
+
+	resource := v1alpha1.GroupVersion.WithResource("nebulaclusters")
+	options := metav1.CreateOptions{}
+	if dryRun == dryRunServer {
+		options.DryRun = []string{metav1.DryRunAll}
+	}
+	_, err = client.Resource(resource).Namespace(option.Namespace).
+		Create(ctx, &unstructured.Unstructured{Object: object}, options)
+	if err != nil {
+		return err
+	}
+	if dryRun == dryRunServer {
+		logger.Infof("nebula graph cluster %s is validated (server dry run)", option.Name)
+		return nil
+	}
+	logger.Infof("nebula graph cluster %s is created in namespace %s", option.Name, option.Namespace)
+
+	if err = saveContext("", option.Name, option.Namespace); err != nil {
+		return err
+	}
+
+	if !wait {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, waitTimeout(10*time.Minute))
+	defer cancel()
+	err = cluster.Wait(ctx, client, option.Name, option.Namespace, cluster.Ready, progressPrinter())
+	if err != nil {
+		return fmt.Errorf("wait for nebula graph cluster %s: %w", option.Name, err)
+	}
+	logger.Infof("nebula graph cluster %s is ready", option.Name)
+	return nil
+}
+
+// progressPrinter returns a function which prints READY/DESIRED of all components when it changes
+func progressPrinter() func(*v1alpha1.NebulaCluster) {
+	var last string
+	return func(nc *v1alpha1.NebulaCluster) {
+		var parts []string
+		for _, component := range cluster.Components {
+			status, desired := cluster.ComponentStatus(nc, component)
+			parts = append(parts, fmt.Sprintf("%s %d/%d", component, status.Workload.ReadyReplicas, desired))
+		}
+		progress := strings.Join(parts, ", ")
+		if progress != last {
+			logger.Infof("%s", progress)
+			last = progress
+		}
+	}
+}
@@ -0,0 +1,271 @@
+/*
+ * Copyright (c) 2023 The nebula-contrib Authors.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *     http://www.apache.org/licenses/LICENSE-2.
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+	"github.com/vesoft-inc/nebula-operator/apis/apps/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/nebula-contrib/ngctl/pkg/config"
+	"github.com/nebula-contrib/ngctl/pkg/logger"
+)
+
+const clusterSelector = "app.kubernetes.io/cluster=%s,app.kubernetes.io/name=nebula-graph"
+
+func deleteCmd() *cobra.Command {
+	var (
+		keepData  bool
+		purgeData bool
+		yes       bool
+	)
+	cmd := &cobra.Command{
+		Use:   "delete NAME",
+		Short: "delete a nebula graph cluster",
+		Long:  "delete a nebula graph cluster, the data volumes are retained unless --purge-data is set.",
+		Example: `  # delete a nebula graph cluster and retain the data volumes
+  ngctl delete nebula --keep-data
+  # delete a nebula graph cluster and its data volumes without confirmation
+  ngctl delete nebula --purge-data --yes
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return errors.New("please specify the name of Nebula Graph cluster")
+			}
+			if keepData && purgeData {
+				return errors.New("--keep-data and --purge-data can not be set at the same time")
+			}
+			return deleteCluster(cmd.Context(), args[0], namespaceOrDefault(), purgeData, yes)
+		},
+	}
+	cmd.PersistentFlags().BoolVar(&keepData, "keep-data", false, "if set, retain the data volumes of the nebula graph cluster, this is the default")
+	cmd.PersistentFlags().BoolVar(&purgeData, "purge-data", false, "if set, reclaim the data volumes of the nebula graph cluster")
+	cmd.PersistentFlags().BoolVarP(&yes, "yes", "y", false, "if set, skip the confirmation prompt")
+	return cmd
+}
+
+func deleteCluster(ctx context.Context, name, namespace string, purgeData, yes bool) error {
+	client, err := newDynamicClient()
+	if err != nil {
+		return err
+	}
+	clientSet, err := newClientSet()
+	if err != nil {
+		return err
+	}
+
+	cluster, err := getCluster(ctx, client, name, namespace)
+	if err != nil {
+		return err
+	}
+
+	volumes, err := showClusterResources(ctx, clientSet, name, namespace, purgeData)
+	if err != nil {
+		return err
+	}
+	if !yes {
+		ok, err := confirm(ctx, stdin, fmt.Sprintf("delete nebula graph cluster %s in namespace %s?", name, namespace))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			logger.Infof("deletion of nebula graph cluster %s is canceled", name)
+			return nil
+		}
+	}
+
+	resource := v1alpha1.GroupVersion.WithResource("nebulaclusters")
+	resourceInterface := client.Resource(resource).Namespace(namespace)
+
+	// the operator reclaims the volumes by itself when enablePVReclaim is set,
+	// turn it off first so the data volumes are retained as requested
+	if !purgeData && cluster.IsPVReclaimEnabled() {
+		patch := []byte(`{"spec":{"enablePVReclaim":false}}`)
+		_, err = resourceInterface.Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+		if err != nil {
+			return err
+		}
+	}
+
+	err = resourceInterface.Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil {
+		return err
+	}
+	logger.Infof("nebula graph cluster %s is deleted", name)
+
+	if purgeData {
+		if err = purgeVolumes(ctx, clientSet, volumes, namespace); err != nil {
+			return err
+		}
+	}
+
+	// only the contexts of the deleted cluster are removed, which is in the kubernetes context in use
+	options := kubeOptions()
+	current, err := options.CurrentContext()
+	if err != nil {
+		return err
+	}
+	removed, err := config.RemoveCluster(config.Context{
+		Cluster:     name,
+		Namespace:   namespace,
+		KubeConfig:  options.KubeConfig,
+		KubeContext: current,
+	})
+	if err != nil {
+		return err
+	}
+	for _, context := range removed {
+		logger.Infof("context %s of nebula graph cluster %s is deleted", context, name)
+	}
+	return nil
+}
+
+// clusterVolumes are the PVCs of a nebula graph cluster and the PVs bound to them
+type clusterVolumes struct {
+	pvcs []corev1.PersistentVolumeClaim
+	pvs  []corev1.PersistentVolume
+}
+
+// showClusterResources prints the resources which belong to the nebula graph cluster and returns its volumes
+func showClusterResources(ctx context.Context, clientSet kubernetes.Interface, name, namespace string, purgeData bool) (*clusterVolumes, error) {
+	selector := fmt.Sprintf(clusterSelector, name)
+	options := metav1.ListOptions{LabelSelector: selector}
+
+	statefulSets, err := clientSet.AppsV1().StatefulSets(namespace).List(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+	services, err := clientSet.CoreV1().Services(namespace).List(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+	volumes, err := getClusterVolumes(ctx, clientSet, options, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	t := table.NewWriter()
+	t.SetOutputMirror(stdout)
+	t.AppendHeader(table.Row{"KIND", "NAME", "ACTION"})
+	t.AppendRow(table.Row{"NebulaCluster", name, "delete"})
+	for _, sts := range statefulSets.Items {
+		t.AppendRow(table.Row{"StatefulSet", sts.Name, "delete"})
+	}
+	for _, svc := range services.Items {
+		t.AppendRow(table.Row{"Service", svc.Name, "delete"})
+	}
+	action := "retain"
+	if purgeData {
+		action = "delete"
+	}
+	for _, pvc := range volumes.pvcs {
+		t.AppendRow(table.Row{"PersistentVolumeClaim", pvc.Name, action})
+	}
+	for _, pv := range volumes.pvs {
+		pvAction := action
+		// PVs with the Delete reclaim policy are removed by the provisioner once their claims are deleted
+		if purgeData && pv.Spec.PersistentVolumeReclaimPolicy != corev1.PersistentVolumeReclaimRetain {
+			pvAction = strings.ToLower(string(pv.Spec.PersistentVolumeReclaimPolicy)) + " by provisioner"
+		}
+		t.AppendRow(table.Row{"PersistentVolume", pv.Name, pvAction})
+	}
+	t.Render()
+	return volumes, nil
+}
+
+// getClusterVolumes returns the PVCs selected by options and the PVs bound to them, the PVs are resolved by the
+// volume names of the claims since the dynamically provisioned PVs do not carry the labels of their claims
+func getClusterVolumes(ctx context.Context, clientSet kubernetes.Interface, options metav1.ListOptions, namespace string) (*clusterVolumes, error) {
+	pvcs, err := clientSet.CoreV1().PersistentVolumeClaims(namespace).List(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+	volumes := &clusterVolumes{pvcs: pvcs.Items}
+	for _, pvc := range pvcs.Items {
+		if pvc.Spec.VolumeName == "" {
+			continue
+		}
+		pv, err := clientSet.CoreV1().PersistentVolumes().Get(ctx, pvc.Spec.VolumeName, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		volumes.pvs = append(volumes.pvs, *pv)
+	}
+	return volumes, nil
+}
+
+// purgeVolumes removes the PVCs of the nebula graph cluster and the retained PVs bound to them
+func purgeVolumes(ctx context.Context, clientSet kubernetes.Interface, volumes *clusterVolumes, namespace string) error {
+	for _, pvc := range volumes.pvcs {
+		err := clientSet.CoreV1().PersistentVolumeClaims(namespace).Delete(ctx, pvc.Name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		logger.InfoS("resource is removed", "kind", "PersistentVolumeClaim", "name", pvc.Name, "namespace", namespace)
+	}
+	// PVs with the Delete reclaim policy are removed by the provisioner
+	for _, pv := range volumes.pvs {
+		if pv.Spec.PersistentVolumeReclaimPolicy != corev1.PersistentVolumeReclaimRetain {
+			continue
+		}
+		err := clientSet.CoreV1().PersistentVolumes().Delete(ctx, pv.Name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		logger.InfoS("resource is removed", "kind", "PersistentVolume", "name", pv.Name)
+	}
+	return nil
+}
+
+// confirm asks the user with prompt and returns true if the answer is yes, it returns the error of ctx
+// if ctx is done before the answer
+func confirm(ctx context.Context, in io.Reader, prompt string) (bool, error) {
+	fmt.Fprintf(stdout, "%s [y/N]: ", prompt)
+	type result struct {
+		answer string
+		err    error
+	}
+	answered := make(chan result, 1)
+	go func() {
+		answer, err := bufio.NewReader(in).ReadString('\n')
+		answered <- result{answer: answer, err: err}
+	}()
+	var r result
+	select {
+	case <-ctx.Done():
+		fmt.Fprintln(stdout)
+		return false, ctx.Err()
+	case r = <-answered:
+	}
+	if r.err != nil && !errors.Is(r.err, io.EOF) {
+		return false, r.err
+	}
+	answer := strings.ToLower(strings.TrimSpace(r.answer))
+	return answer == "y" || answer == "yes", nil
+}
@@ -0,0 +1,550 @@
+/*
+ * Copyright (c) 2023 The nebula-contrib Authors.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *     http://www.apache.org/licenses/LICENSE-2.
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+	"github.com/vesoft-inc/nebula-operator/apis/apps/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/nebula-contrib/ngctl/pkg/cluster"
+	"github.com/nebula-contrib/ngctl/pkg/printer"
+)
+
+const (
+	clusterTarget = "cluster"
+	// unhealthyReason is the reason of the events of probe failures
+	unhealthyReason = "Unhealthy"
+	// failedSchedulingReason is the reason of the events of scheduling failures
+	failedSchedulingReason = "FailedScheduling"
+)
+
+func describeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "describe [cluster|COMPONENT-ORDINAL]",
+		Short: "show the details of the nebula graph cluster or a pod of a component",
+		Long: `show the details of the nebula graph cluster or a pod of a component together with the related events.
+the cluster is described with its status conditions, a pod is described with its container states, last termination
+reasons, probe failures and scheduling messages. the pod is addressed by its component and ordinal such as storaged-2.`,
+		Example: `  # describe the nebula graph cluster
+  ngctl describe
+  # describe the third storaged pod
+  ngctl describe storaged-2
+`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			target := clusterTarget
+			if len(args) > 0 {
+				target = args[0]
+			}
+			return describe(cmd.Context(), target)
+		},
+	}
+	return cmd
+}
+
+func describe(ctx context.Context, target string) error {
+	if err := printer.Validate(output); err != nil {
+		return err
+	}
+	if target != clusterTarget {
+		if _, _, err := parsePodTarget(target); err != nil {
+			return err
+		}
+	}
+	name, namespace, err := selectCluster(ctx)
+	if err != nil {
+		return err
+	}
+	clientSet, err := newClientSet()
+	if err != nil {
+		return err
+	}
+
+	var result printer.Printable
+	if target == clusterTarget {
+		result, err = describeCluster(ctx, clientSet, name, namespace)
+	} else {
+		result, err = describePod(ctx, clientSet, target, name, namespace)
+	}
+	if err != nil {
+		return err
+	}
+	return printer.Print(stdout, output, result)
+}
+
+// clusterDescription is the json and yaml output of describing a nebula graph cluster
+type clusterDescription struct {
+	Cluster    clusterMeta         `json:"cluster"`
+	Version    string              `json:"version"`
+	Overview   []componentOverview `json:"overview"`
+	Conditions []conditionSummary  `json:"conditions"`
+	Events     []eventSummary      `json:"events"`
+
+	cluster *v1alpha1.NebulaCluster
+	events  []corev1.Event
+}
+
+// podDescription is the json and yaml output of describing a pod of a component
+type podDescription struct {
+	Name       string             `json:"name"`
+	Namespace  string             `json:"namespace"`
+	Component  string             `json:"component"`
+	Node       string             `json:"node"`
+	PodIP      string             `json:"podIP"`
+	Phase      string             `json:"phase"`
+	StartTime  *metav1.Time       `json:"startTime,omitempty"`
+	Issue      string             `json:"issue,omitempty"`
+	Conditions []conditionSummary `json:"conditions"`
+	Containers []containerSummary `json:"containers"`
+	// Scheduling are the messages of the scheduling failures
+	Scheduling []string `json:"scheduling,omitempty"`
+	// ProbeFailures are the events of the failed liveness, readiness and startup probes
+	ProbeFailures []eventSummary `json:"probeFailures,omitempty"`
+	Events        []eventSummary `json:"events"`
+
+	pod    *corev1.Pod
+	events []corev1.Event
+}
+
+// describeSource is the object evaluated by the jsonpath and go-template formats
+type describeSource struct {
+	Object interface{}    `json:"object"`
+	Events []corev1.Event `json:"events"`
+}
+
+// conditionSummary is a status condition of a nebula graph cluster or a pod
+type conditionSummary struct {
+	Type               string      `json:"type"`
+	Status             string      `json:"status"`
+	Reason             string      `json:"reason,omitempty"`
+	Message            string      `json:"message,omitempty"`
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+}
+
+// containerSummary is the state of a container of a pod
+type containerSummary struct {
+	Name     string `json:"name"`
+	Image    string `json:"image"`
+	Init     bool   `json:"init,omitempty"`
+	State    string `json:"state"`
+	Ready    bool   `json:"ready"`
+	Restarts int32  `json:"restarts"`
+	// LastTermination is the reason of the last termination of the container, it is empty if the container has
+	// never been restarted
+	LastTermination string `json:"lastTermination,omitempty"`
+}
+
+// eventSummary is an event of a nebula graph cluster or a pod
+type eventSummary struct {
+	Type           string      `json:"type"`
+	Reason         string      `json:"reason"`
+	Count          int32       `json:"count"`
+	FirstTimestamp metav1.Time `json:"firstTimestamp"`
+	LastTimestamp  metav1.Time `json:"lastTimestamp"`
+	From           string      `json:"from"`
+	Message        string      `json:"message"`
+}
+
+func (d *clusterDescription) Tables() []printer.Table {
+	return []printer.Table{
+		{
+			Rows: []table.Row{
+				{"Name", d.Cluster.Name},
+				{"Namespace", d.Cluster.Namespace},
+				{"Version", d.Version},
+				{"CreationTimestamp", d.Cluster.CreationTimestamp},
+			},
+		},
+		overviewTable(d.Overview),
+		conditionsTable(d.Conditions),
+		eventsTable("Events", d.Events),
+	}
+}
+
+func (d *clusterDescription) Names() []string {
+	return []string{"nebulacluster/" + d.Cluster.Name}
+}
+
+func (d *clusterDescription) Object() interface{} {
+	return &describeSource{Object: d.cluster, Events: d.events}
+}
+
+// Objects returns the events, the rows of the custom-columns format
+func (d *clusterDescription) Objects() []interface{} {
+	return eventObjects(d.events)
+}
+
+func (d *podDescription) Tables() []printer.Table {
+	summary := printer.Table{
+		Rows: []table.Row{
+			{"Name", d.Name},
+			{"Namespace", d.Namespace},
+			{"Component", d.Component},
+			{"Node", d.Node},
+			{"IP", d.PodIP},
+			{"Phase", d.Phase},
+		},
+	}
+	if d.StartTime != nil {
+		summary.Rows = append(summary.Rows, table.Row{"StartTime", *d.StartTime})
+	}
+	if d.Issue != "" {
+		summary.Rows = append(summary.Rows, table.Row{"Issue", d.Issue})
+	}
+
+	containers := printer.Table{
+		Title:       "Containers",
+		Header:      table.Row{"Name", "State", "Ready", "Restarts", "Last Termination", "Image"},
+		WideColumns: 1,
+	}
+	for _, c := range d.Containers {
+		name := c.Name
+		if c.Init {
+			name += " (init)"
+		}
+		containers.Rows = append(containers.Rows, table.Row{name, c.State, c.Ready, c.Restarts,
+			valueOrNone(c.LastTermination), c.Image})
+	}
+
+	tables := []printer.Table{summary, conditionsTable(d.Conditions), containers}
+	if len(d.Scheduling) > 0 {
+		scheduling := printer.Table{Title: "Scheduling"}
+		for _, message := range d.Scheduling {
+			scheduling.Rows = append(scheduling.Rows, table.Row{message})
+		}
+		tables = append(tables, scheduling)
+	}
+	if len(d.ProbeFailures) > 0 {
+		tables = append(tables, eventsTable("Probe Failures", d.ProbeFailures))
+	}
+	return append(tables, eventsTable("Events", d.Events))
+}
+
+func (d *podDescription) Names() []string {
+	return []string{"pod/" + d.Name}
+}
+
+func (d *podDescription) Object() interface{} {
+	return &describeSource{Object: d.pod, Events: d.events}
+}
+
+// Objects returns the events, the rows of the custom-columns format
+func (d *podDescription) Objects() []interface{} {
+	return eventObjects(d.events)
+}
+
+func describeCluster(ctx context.Context, clientSet kubernetes.Interface, name, namespace string) (*clusterDescription, error) {
+	client, err := newDynamicClient()
+	if err != nil {
+		return nil, err
+	}
+	nc, err := getCluster(ctx, client, name, namespace)
+	if err != nil {
+		return nil, err
+	}
+	events, err := listEvents(ctx, clientSet, "NebulaCluster", &nc.ObjectMeta)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &clusterDescription{
+		Cluster: clusterMeta{
+			Name:              nc.Name,
+			Namespace:         nc.Namespace,
+			CreationTimestamp: nc.CreationTimestamp,
+		},
+		Overview:   componentOverviews(nc),
+		Conditions: []conditionSummary{},
+		Events:     eventSummaries(events),
+		cluster:    nc,
+		events:     events,
+	}
+	if nc.Spec.Graphd != nil {
+		d.Version = nc.Spec.Graphd.Version
+	}
+	for _, condition := range nc.Status.Conditions {
+		d.Conditions = append(d.Conditions, conditionSummary{
+			Type:               string(condition.Type),
+			Status:             string(condition.Status),
+			Reason:             condition.Reason,
+			Message:            condition.Message,
+			LastTransitionTime: condition.LastTransitionTime,
+		})
+	}
+	return d, nil
+}
+
+func describePod(ctx context.Context, clientSet kubernetes.Interface, target, name, namespace string) (*podDescription, error) {
+	pod, component, err := resolvePod(ctx, clientSet, target, name, namespace)
+	if err != nil {
+		return nil, err
+	}
+	events, err := listEvents(ctx, clientSet, "Pod", &pod.ObjectMeta)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &podDescription{
+		Name:       pod.Name,
+		Namespace:  pod.Namespace,
+		Component:  component,
+		Node:       pod.Spec.NodeName,
+		PodIP:      pod.Status.PodIP,
+		Phase:      string(pod.Status.Phase),
+		StartTime:  pod.Status.StartTime,
+		Issue:      cluster.PodIssue(pod),
+		Conditions: []conditionSummary{},
+		Containers: []containerSummary{},
+		Events:     eventSummaries(events),
+		pod:        pod,
+		events:     events,
+	}
+	for _, condition := range pod.Status.Conditions {
+		d.Conditions = append(d.Conditions, conditionSummary{
+			Type:               string(condition.Type),
+			Status:             string(condition.Status),
+			Reason:             condition.Reason,
+			Message:            condition.Message,
+			LastTransitionTime: condition.LastTransitionTime,
+		})
+		if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionFalse && condition.Message != "" {
+			d.Scheduling = append(d.Scheduling, condition.Message)
+		}
+	}
+	d.Containers = append(d.Containers, containerSummaries(pod.Spec.InitContainers, pod.Status.InitContainerStatuses, true)...)
+	d.Containers = append(d.Containers, containerSummaries(pod.Spec.Containers, pod.Status.ContainerStatuses, false)...)
+	for _, event := range d.Events {
+		switch event.Reason {
+		case unhealthyReason:
+			d.ProbeFailures = append(d.ProbeFailures, event)
+		case failedSchedulingReason:
+			if !containsString(d.Scheduling, event.Message) {
+				d.Scheduling = append(d.Scheduling, event.Message)
+			}
+		}
+	}
+	return d, nil
+}
+
+// containerSummaries returns the states of containers in the order of the spec, a container without status is waiting
+// to be created
+func containerSummaries(containers []corev1.Container, statuses []corev1.ContainerStatus, init bool) []containerSummary {
+	summaries := make([]containerSummary, 0, len(containers))
+	for _, container := range containers {
+		summary := containerSummary{Name: container.Name, Image: container.Image, Init: init, State: "Waiting"}
+		for _, status := range statuses {
+			if status.Name != container.Name {
+				continue
+			}
+			summary.State = containerState(status.State)
+			summary.Ready = status.Ready
+			summary.Restarts = status.RestartCount
+			if terminated := status.LastTerminationState.Terminated; terminated != nil {
+				summary.LastTermination = terminationReason(terminated)
+			}
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries
+}
+
+// containerState returns a state such as `Running for 3d`, `Waiting: CrashLoopBackOff` or `Terminated: Error, exit code 1`
+func containerState(state corev1.ContainerState) string {
+	switch {
+	case state.Running != nil:
+		return "Running for " + age(state.Running.StartedAt)
+	case state.Waiting != nil:
+		if state.Waiting.Message != "" {
+			return fmt.Sprintf("Waiting: %s: %s", state.Waiting.Reason, state.Waiting.Message)
+		}
+		return "Waiting: " + state.Waiting.Reason
+	case state.Terminated != nil:
+		return "Terminated: " + terminationReason(state.Terminated)
+	default:
+		return "Waiting"
+	}
+}
+
+// terminationReason returns the reason and the exit code of a terminated container such as `OOMKilled, exit code 137, 5m ago`
+func terminationReason(terminated *corev1.ContainerStateTerminated) string {
+	reason := terminated.Reason
+	if reason == "" {
+		reason = "Terminated"
+	}
+	if terminated.Signal != 0 {
+		reason = fmt.Sprintf("%s, signal %d", reason, terminated.Signal)
+	} else {
+		reason = fmt.Sprintf("%s, exit code %d", reason, terminated.ExitCode)
+	}
+	if !terminated.FinishedAt.IsZero() {
+		reason = fmt.Sprintf("%s, %s ago", reason, age(terminated.FinishedAt))
+	}
+	if terminated.Message != "" {
+		reason = fmt.Sprintf("%s: %s", reason, strings.TrimSpace(terminated.Message))
+	}
+	return reason
+}
+
+// listEvents lists the events of the object of kind ordered by their last occurrence, the events are filtered here
+// as well in case the field selector is not supported
+func listEvents(ctx context.Context, clientSet kubernetes.Interface, kind string, object *metav1.ObjectMeta) ([]corev1.Event, error) {
+	selector := fmt.Sprintf("involvedObject.kind=%s,involvedObject.name=%s", kind, object.Name)
+	list, err := clientSet.CoreV1().Events(object.Namespace).List(ctx, metav1.ListOptions{FieldSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+	events := make([]corev1.Event, 0, len(list.Items))
+	for _, event := range list.Items {
+		involved := event.InvolvedObject
+		if involved.Kind != kind || involved.Name != object.Name {
+			continue
+		}
+		// the events of a deleted object of the same name such as a recreated pod are ignored
+		if involved.UID != "" && object.UID != "" && involved.UID != object.UID {
+			continue
+		}
+		events = append(events, event)
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return lastTimestamp(&events[i]).Time.Before(lastTimestamp(&events[j]).Time)
+	})
+	return events, nil
+}
+
+func eventSummaries(events []corev1.Event) []eventSummary {
+	summaries := make([]eventSummary, 0, len(events))
+	for i := range events {
+		event := &events[i]
+		from := event.Source.Component
+		if from == "" {
+			from = event.ReportingController
+		}
+		count := event.Count
+		if count == 0 {
+			count = 1
+		}
+		summaries = append(summaries, eventSummary{
+			Type:           event.Type,
+			Reason:         event.Reason,
+			Count:          count,
+			FirstTimestamp: firstTimestamp(event),
+			LastTimestamp:  lastTimestamp(event),
+			From:           from,
+			Message:        strings.TrimSpace(event.Message),
+		})
+	}
+	return summaries
+}
+
+func eventsTable(title string, events []eventSummary) printer.Table {
+	if len(events) == 0 {
+		return printer.Table{Title: title, Rows: []table.Row{{none}}}
+	}
+	t := printer.Table{
+		Title:  title,
+		Header: table.Row{"Type", "Reason", "Age", "From", "Message"},
+	}
+	for _, e := range events {
+		// the age of a repeated event is like `2m (x5 over 10m)` as kubectl describe
+		eventAge := age(e.LastTimestamp)
+		if e.Count > 1 {
+			eventAge = fmt.Sprintf("%s (x%d over %s)", eventAge, e.Count, age(e.FirstTimestamp))
+		}
+		t.Rows = append(t.Rows, table.Row{e.Type, e.Reason, eventAge, e.From, e.Message})
+	}
+	return t
+}
+
+func conditionsTable(conditions []conditionSummary) printer.Table {
+	if len(conditions) == 0 {
+		return printer.Table{Title: "Conditions", Rows: []table.Row{{none}}}
+	}
+	t := printer.Table{
+		Title:  "Conditions",
+		Header: table.Row{"Type", "Status", "Reason", "Age", "Message"},
+	}
+	for _, c := range conditions {
+		t.Rows = append(t.Rows, table.Row{c.Type, c.Status, valueOrNone(c.Reason), age(c.LastTransitionTime), c.Message})
+	}
+	return t
+}
+
+func eventObjects(events []corev1.Event) []interface{} {
+	items := make([]interface{}, 0, len(events))
+	for i := range events {
+		items = append(items, &events[i])
+	}
+	return items
+}
+
+// lastTimestamp returns the time of the last occurrence of an event, the events recorded by the events.k8s.io api
+// only have the event time
+func lastTimestamp(event *corev1.Event) metav1.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp
+	case !event.EventTime.IsZero():
+		return metav1.NewTime(event.EventTime.Time)
+	case !event.FirstTimestamp.IsZero():
+		return event.FirstTimestamp
+	default:
+		return event.CreationTimestamp
+	}
+}
+
+func firstTimestamp(event *corev1.Event) metav1.Time {
+	switch {
+	case !event.FirstTimestamp.IsZero():
+		return event.FirstTimestamp
+	case !event.EventTime.IsZero():
+		return metav1.NewTime(event.EventTime.Time)
+	default:
+		return lastTimestamp(event)
+	}
+}
+
+// age returns the human-readable duration since t, `<unknown>` is returned if t is not set
+func age(t metav1.Time) string {
+	if t.IsZero() {
+		return "<unknown>"
+	}
+	return duration.HumanDuration(time.Since(t.Time))
+}
+
+func valueOrNone(value string) string {
+	if value == "" {
+		return none
+	}
+	return value
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,181 @@
+/*
+ * Copyright (c) 2023 The nebula-contrib Authors.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *     http://www.apache.org/licenses/LICENSE-2.
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/vesoft-inc/nebula-operator/apis/apps/v1alpha1"
+	"k8s.io/client-go/dynamic"
+	utilexec "k8s.io/client-go/util/exec"
+	"sigs.k8s.io/yaml"
+
+	"github.com/nebula-contrib/ngctl/pkg/diff"
+	"github.com/nebula-contrib/ngctl/pkg/manifest"
+)
+
+// ErrDiffer is returned by the diff command when the specs are different, ngctl exits with 1
+var ErrDiffer = errors.New("nebula graph cluster specs are different")
+
+// diffErrorCode is the exit code of the errors of diff, like kubectl diff it is greater than 1 so that the errors are
+// told apart from the differences
+const diffErrorCode = 2
+
+const (
+	diffUnified    = "unified"
+	diffStructured = "structured"
+)
+
+func diffCmd() *cobra.Command {
+	var (
+		file   string
+		format string
+	)
+	cmd := &cobra.Command{
+		Use:   "diff [-f FILE | CLUSTER_A CLUSTER_B]",
+		Short: "diff the spec of nebula graph clusters",
+		Long: `diff the spec of a live nebula graph cluster against a local manifest or another cluster.
+status, managed fields and default values are ignored, it exits with 1 if the specs are different and 2 on errors.`,
+		Example: `  # diff the live cluster against cluster.yaml
+  ngctl diff -f cluster.yaml
+  # diff two live clusters, the namespace can be specified as NAMESPACE/NAME
+  ngctl diff staging/nebula prod/nebula --format structured
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := runDiff(cmd.Context(), file, args, format)
+			if errors.Is(err, ErrDiffer) {
+				// the differences are already printed
+				cmd.SilenceErrors, cmd.SilenceUsage = true, true
+				return err
+			}
+			if err != nil {
+				return utilexec.CodeExitError{Err: err, Code: diffErrorCode}
+			}
+			return nil
+		},
+	}
+	cmd.PersistentFlags().StringVarP(&file, "file", "f", "", "path of the nebula graph cluster manifest, - means stdin")
+	cmd.PersistentFlags().StringVar(&format, "format", diffUnified, "output format of the differences, one of unified|structured")
+	return cmd
+}
+
+type diffSide struct {
+	name string
+	spec map[string]interface{}
+}
+
+func runDiff(ctx context.Context, file string, args []string, format string) error {
+	if format != diffUnified && format != diffStructured {
+		return fmt.Errorf("unsupported format %s, one of unified|structured", format)
+	}
+	if file == "" && len(args) != 2 {
+		return errors.New("please specify a manifest file by -f or two nebula graph clusters")
+	}
+	if file != "" && len(args) != 0 {
+		return errors.New("-f and nebula graph clusters can not be set at the same time")
+	}
+	return diffClusters(ctx, file, args, namespaceOrDefault(), format)
+}
+
+func diffClusters(ctx context.Context, file string, args []string, namespace, format string) error {
+	client, err := newDynamicClient()
+	if err != nil {
+		return err
+	}
+
+	var left, right *diffSide
+	if file != "" {
+		content, err := manifest.Read(file, stdin)
+		if err != nil {
+			return err
+		}
+		objects, err := manifest.DecodeClusters(content)
+		if err != nil {
+			return err
+		}
+		if len(objects) != 1 {
+			return fmt.Errorf("expect 1 nebula graph cluster in %s, but got %d", file, len(objects))
+		}
+		local, err := manifest.ToCluster(objects[0])
+		if err != nil {
+			return err
+		}
+		if local.Namespace == "" {
+			local.Namespace = namespace
+		}
+		if left, err = liveSide(ctx, client, local.Namespace+"/"+local.Name, namespace); err != nil {
+			return err
+		}
+		if right, err = newDiffSide(file, local); err != nil {
+			return err
+		}
+	} else {
+		if left, err = liveSide(ctx, client, args[0], namespace); err != nil {
+			return err
+		}
+		if right, err = liveSide(ctx, client, args[1], namespace); err != nil {
+			return err
+		}
+	}
+
+	changes := diff.Compare(left.spec, right.spec)
+	if len(changes) == 0 {
+		return nil
+	}
+	if format == diffStructured {
+		diff.Print(stdout, changes)
+		return ErrDiffer
+	}
+	oldText, err := yaml.Marshal(left.spec)
+	if err != nil {
+		return err
+	}
+	newText, err := yaml.Marshal(right.spec)
+	if err != nil {
+		return err
+	}
+	diff.Unified(stdout, left.name, right.name, string(oldText), string(newText))
+	return ErrDiffer
+}
+
+// liveSide fetches a live nebula graph cluster referenced by NAME or NAMESPACE/NAME
+func liveSide(ctx context.Context, client dynamic.Interface, ref, namespace string) (*diffSide, error) {
+	name := ref
+	if i := strings.Index(ref, "/"); i >= 0 {
+		namespace, name = ref[:i], ref[i+1:]
+	}
+	nc, err := getCluster(ctx, client, name, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return newDiffSide(namespace+"/"+name, nc)
+}
+
+// newDiffSide normalizes the spec of the nebula graph cluster by dropping the empty and default values
+func newDiffSide(name string, nc *v1alpha1.NebulaCluster) (*diffSide, error) {
+	object, err := clusterObject(nc)
+	if err != nil {
+		return nil, err
+	}
+	spec, ok := object.Object["spec"].(map[string]interface{})
+	if !ok {
+		spec = map[string]interface{}{}
+	}
+	diff.Prune(spec, manifest.Defaults)
+	return &diffSide{name: name, spec: spec}, nil
+}
@@ -0,0 +1,80 @@
+/*
+ * Copyright (c) 2023 The nebula-contrib Authors.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *     http://www.apache.org/licenses/LICENSE-2.
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	dryRunNone   = "none"
+	dryRunClient = "client"
+	dryRunServer = "server"
+)
+
+// dryRunAnnotation marks the commands which support --dry-run, the others reject it
+// so that a dry run never changes anything by mistake
+const dryRunAnnotation = "ngctl/dry-run"
+
+// supportDryRun marks cmd to support --dry-run
+func supportDryRun(cmd *cobra.Command) *cobra.Command {
+	if cmd.Annotations == nil {
+		cmd.Annotations = map[string]string{}
+	}
+	cmd.Annotations[dryRunAnnotation] = "true"
+	return cmd
+}
+
+// checkDryRun validates --dry-run for cmd
+func checkDryRun(cmd *cobra.Command) error {
+	switch dryRun {
+	case dryRunNone:
+		return nil
+	case dryRunClient, dryRunServer:
+	default:
+		return fmt.Errorf("unsupported dry run mode %s, one of none|client|server", dryRun)
+	}
+	if cmd.Annotations[dryRunAnnotation] == "" {
+		return fmt.Errorf("--dry-run is not supported by %s", cmd.CommandPath())
+	}
+	return nil
+}
+
+// printManifests prints the objects as yaml documents, the kinds of the typed objects are filled in
+func printManifests(w io.Writer, objects ...runtime.Object) error {
+	for _, object := range objects {
+		object = object.DeepCopyObject()
+		if object.GetObjectKind().GroupVersionKind().Empty() {
+			kinds, _, err := scheme.Scheme.ObjectKinds(object)
+			if err != nil {
+				return err
+			}
+			object.GetObjectKind().SetGroupVersionKind(kinds[0])
+		}
+		content, err := yaml.Marshal(object)
+		if err != nil {
+			return err
+		}
+		if _, err = fmt.Fprintf(w, "---\n%s", content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
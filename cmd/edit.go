@@ -0,0 +1,225 @@
+/*
+ * Copyright (c) 2023 The nebula-contrib Authors.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *     http://www.apache.org/licenses/LICENSE-2.
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/vesoft-inc/nebula-operator/apis/apps/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"github.com/nebula-contrib/ngctl/pkg/diff"
+	"github.com/nebula-contrib/ngctl/pkg/logger"
+	"github.com/nebula-contrib/ngctl/pkg/manifest"
+)
+
+const defaultEditor = "vi"
+
+const editHeader = `# Please edit the nebula graph cluster below. Lines beginning with a '#' will be ignored,
+# and an empty file will abort the edit. If an error occurs while saving this file will be
+# reopened with the relevant failures.
+#
+`
+
+func editCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "edit",
+		Short: "edit the nebula graph cluster in use",
+		Long:  "edit the spec of the nebula graph cluster in use with $EDITOR.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return edit(cmd.Context())
+		},
+	}
+	return cmd
+}
+
+func edit(ctx context.Context) error {
+	name, namespace, err := selectCluster(ctx)
+	if err != nil {
+		return err
+	}
+
+	client, err := newDynamicClient()
+	if err != nil {
+		return err
+	}
+	resourceInterface := client.Resource(v1alpha1.GroupVersion.WithResource("nebulaclusters")).Namespace(namespace)
+	live, err := resourceInterface.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	original, err := editableYaml(live)
+	if err != nil {
+		return err
+	}
+	content := original
+	var (
+		edited *unstructured.Unstructured
+		// failed is the content of the last attempt which failed the validation
+		failed []byte
+	)
+	for {
+		result, err := launchEditor(content)
+		if err != nil {
+			return err
+		}
+		stripped := stripComments(result)
+		if len(bytes.TrimSpace(stripped)) == 0 {
+			logger.Infof("edit is canceled, the file is empty")
+			return nil
+		}
+		if bytes.Equal(stripped, stripComments(original)) {
+			logger.Infof("edit is canceled, no changes are made")
+			return nil
+		}
+		// the invalid content is saved again without changes, abort instead of reopening it forever as kubectl does
+		if failed != nil && bytes.Equal(stripped, failed) {
+			return errors.New("edit is canceled, the invalid changes are not fixed")
+		}
+		edited, err = validateEdited(stripped, name, namespace)
+		if err == nil {
+			break
+		}
+		// reopen the editor with the error inlined
+		failed = stripped
+		content = append([]byte(editErrorHeader("ERROR: "+err.Error())), stripped...)
+	}
+
+	updated := live.DeepCopy()
+	updated.SetLabels(edited.GetLabels())
+	// the annotations hidden from the editor, such as the last applied configuration of kubectl apply, are kept
+	annotations := hiddenAnnotations(live)
+	for key, value := range edited.GetAnnotations() {
+		annotations[key] = value
+	}
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	updated.SetAnnotations(annotations)
+	updated.Object["spec"] = edited.Object["spec"]
+	result, err := resourceInterface.Update(ctx, updated, metav1.UpdateOptions{FieldManager: fieldManager})
+	if err != nil {
+		return err
+	}
+
+	changes := diff.Compare(diff.Normalize(live), diff.Normalize(result))
+	logger.Infof("nebula graph cluster %s is edited", name)
+	diff.Print(stdout, changes)
+	return nil
+}
+
+// editableYaml renders the nebula graph cluster without status and server maintained metadata
+func editableYaml(object *unstructured.Unstructured) ([]byte, error) {
+	content := diff.Normalize(object)
+	// the zero values are kept, the spec is replaced by the edited one and they may be set explicitly
+	diff.PruneNil(content)
+	data, err := yaml.Marshal(content)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(editHeader), data...), nil
+}
+
+// hiddenAnnotations returns the annotations of object which are not rendered by editableYaml
+func hiddenAnnotations(object *unstructured.Unstructured) map[string]string {
+	visible, _, _ := unstructured.NestedStringMap(diff.Normalize(object), "metadata", "annotations")
+	hidden := map[string]string{}
+	for key, value := range object.GetAnnotations() {
+		if _, ok := visible[key]; !ok {
+			hidden[key] = value
+		}
+	}
+	return hidden
+}
+
+// validateEdited validates the edited content against the v1alpha1 types
+func validateEdited(content []byte, name, namespace string) (*unstructured.Unstructured, error) {
+	objects, err := manifest.DecodeClusters(content)
+	if err != nil {
+		return nil, err
+	}
+	if len(objects) != 1 {
+		return nil, fmt.Errorf("expect 1 nebula graph cluster, but got %d", len(objects))
+	}
+	object := objects[0]
+	if object.GetName() != name {
+		return nil, fmt.Errorf("name can not be changed from %s to %s", name, object.GetName())
+	}
+	if object.GetNamespace() != "" && object.GetNamespace() != namespace {
+		return nil, fmt.Errorf("namespace can not be changed from %s to %s", namespace, object.GetNamespace())
+	}
+	if _, err = manifest.ToClusterStrict(object); err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+// launchEditor opens content in $EDITOR and returns the edited content
+func launchEditor(content []byte) ([]byte, error) {
+	file, err := os.CreateTemp("", "ngctl-edit-*.yaml")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = os.Remove(file.Name())
+	}()
+	if _, err = file.Write(content); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	if err = file.Close(); err != nil {
+		return nil, err
+	}
+
+	editor := strings.Fields(os.Getenv("EDITOR"))
+	if len(editor) == 0 {
+		editor = []string{defaultEditor}
+	}
+	command := exec.Command(editor[0], append(editor[1:], file.Name())...)
+	command.Stdin, command.Stdout, command.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err = command.Run(); err != nil {
+		return nil, fmt.Errorf("run editor %s: %w", editor[0], err)
+	}
+	return os.ReadFile(file.Name())
+}
+
+func stripComments(content []byte) []byte {
+	var buf bytes.Buffer
+	for _, line := range strings.SplitAfter(string(content), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		buf.WriteString(line)
+	}
+	return buf.Bytes()
+}
+
+// editErrorHeader returns the edit header followed by text as comments
+func editErrorHeader(text string) string {
+	var buf strings.Builder
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		buf.WriteString("# " + line + "\n")
+	}
+	return editHeader + buf.String() + "#\n"
+}
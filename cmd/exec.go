@@ -0,0 +1,194 @@
+/*
+ * Copyright (c) 2023 The nebula-contrib Authors.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *     http://www.apache.org/licenses/LICENSE-2.
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/docker/cli/cli/streams"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/nebula-contrib/ngctl/pkg/exec"
+	"github.com/nebula-contrib/ngctl/pkg/logger"
+)
+
+// shellCommand starts bash if it is available in the container, otherwise sh
+var shellCommand = []string{"/bin/sh", "-c", "if command -v bash >/dev/null 2>&1; then exec bash; else exec sh; fi"}
+
+func execCmd() *cobra.Command {
+	var (
+		container   string
+		attachStdin bool
+		tty         bool
+	)
+	cmd := &cobra.Command{
+		Use:   "exec COMPONENT[-ORDINAL] -- COMMAND [ARG...]",
+		Short: "execute a command in a pod of a component",
+		Long: `execute a command in a pod of a component of the nebula graph cluster.
+the pod is addressed by its component and ordinal such as storaged-1, the ordinal defaults to 0.`,
+		Example: `  # show the disk usage of the second storaged pod
+  ngctl exec storaged-1 -- df -h
+  # print the config of graphd
+  ngctl exec graphd -- cat /usr/local/nebula/etc/nebula-graphd.conf
+  # run top in metad-0 with a terminal
+  ngctl exec metad-0 -it -- top
+`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cmd.ArgsLenAtDash() != 1 || len(args) < 2 {
+				return errors.New("please specify the pod and the command like ngctl exec storaged-1 -- df -h")
+			}
+			return execInPod(cmd.Context(), args[0], container, args[1:], attachStdin, tty)
+		},
+	}
+	cmd.Flags().StringVar(&container, "container", "", "name of the container, the container of the component is used if empty")
+	cmd.Flags().BoolVarP(&attachStdin, "stdin", "i", false, "if set, pass stdin to the command")
+	cmd.Flags().BoolVarP(&tty, "tty", "t", false, "if set, allocate a terminal for the command")
+	return cmd
+}
+
+func shellCmd() *cobra.Command {
+	var container string
+	cmd := &cobra.Command{
+		Use:   "shell COMPONENT[-ORDINAL]",
+		Short: "start a shell in a pod of a component",
+		Long: `start an interactive shell in a pod of a component of the nebula graph cluster, bash is used if it is available.
+the pod is addressed by its component and ordinal such as graphd-1, the ordinal defaults to 0.`,
+		Example: `  # start a shell in graphd-0
+  ngctl shell graphd
+  # start a shell in the third storaged pod
+  ngctl shell storaged-2
+`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return execInPod(cmd.Context(), args[0], container, shellCommand, true, true)
+		},
+	}
+	cmd.Flags().StringVar(&container, "container", "", "name of the container, the container of the component is used if empty")
+	return cmd
+}
+
+func execInPod(ctx context.Context, target, container string, command []string, attachStdin, tty bool) error {
+	name, namespace, err := selectCluster(ctx)
+	if err != nil {
+		return err
+	}
+	clientSet, err := newClientSet()
+	if err != nil {
+		return err
+	}
+	pod, component, err := resolvePod(ctx, clientSet, target, name, namespace)
+	if err != nil {
+		return err
+	}
+	if container == "" {
+		container = componentContainer(pod, component)
+	}
+
+	options := &exec.Options{
+		Namespace: namespace,
+		Pod:       pod.Name,
+		Container: container,
+		Command:   command,
+		Stdout:    streams.NewOut(stdout),
+		Stderr:    stderr,
+	}
+	if attachStdin {
+		in := exec.NewStdin(stdin)
+		if tty && !in.IsTerminal() {
+			logger.Warningf("stdin is not a terminal, the terminal is not allocated")
+			tty = false
+		}
+		options.Stdin = in
+	}
+	options.TTY = tty
+
+	conf, err := newRESTConfig()
+	if err != nil {
+		return err
+	}
+	logger.V(1).Infof("execute %s in container %s of pod %s", strings.Join(command, " "), container, pod.Name)
+	return exec.Run(ctx, clientSet, conf, options)
+}
+
+// resolvePod returns the pod of the nebula graph cluster name addressed by target such as storaged-1 and its
+// component, the ordinal defaults to 0. the available pods of the component are listed if the pod is not found
+func resolvePod(ctx context.Context, clientSet kubernetes.Interface, target, name, namespace string) (*corev1.Pod, string, error) {
+	component, ordinal, err := parsePodTarget(target)
+	if err != nil {
+		return nil, "", err
+	}
+	if ordinal < 0 {
+		ordinal = 0
+	}
+
+	podName := fmt.Sprintf("%s-%s-%d", name, component, ordinal)
+	pod, err := clientSet.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+	if err == nil {
+		return pod, component, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return nil, "", err
+	}
+	pods, listErr := getComponentPods(ctx, clientSet, component, name, namespace, false)
+	if listErr != nil || len(pods.Items) == 0 {
+		return nil, "", fmt.Errorf("no %s pods of nebula graph cluster %s are found in namespace %s", component, name, namespace)
+	}
+	available := make([]string, 0, len(pods.Items))
+	for _, p := range pods.Items {
+		available = append(available, strings.TrimPrefix(p.Name, name+"-"))
+	}
+	return nil, "", fmt.Errorf("pod %s is not found, the %s pods are %s", podName, component, strings.Join(available, ", "))
+}
+
+// parsePodTarget parses a pod addressed by its component and ordinal such as storaged-1, the ordinal is -1 if it is
+// omitted such as graphd
+func parsePodTarget(target string) (string, int, error) {
+	component, ordinal := target, -1
+	if i := strings.LastIndex(target, "-"); i > 0 {
+		n, err := strconv.Atoi(target[i+1:])
+		if err != nil || n < 0 {
+			return "", 0, fmt.Errorf("invalid pod %s, it should be like graphd or storaged-1", target)
+		}
+		component, ordinal = target[:i], n
+	}
+	switch component {
+	case graphd, metad, storaged:
+		return component, ordinal, nil
+	default:
+		return "", 0, fmt.Errorf("unsupported component %s, one of graphd|metad|storaged", component)
+	}
+}
+
+// componentContainer returns the container of the component in pod, the first container is returned if no container
+// is named after the component
+func componentContainer(pod *corev1.Pod, component string) string {
+	for _, container := range pod.Spec.Containers {
+		if container.Name == component {
+			return container.Name
+		}
+	}
+	if len(pod.Spec.Containers) > 0 {
+		return pod.Spec.Containers[0].Name
+	}
+	return ""
+}
@@ -17,17 +17,17 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"os"
+	"sort"
 	"time"
 
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/spf13/cobra"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
 	"k8s.io/client-go/kubernetes"
 
-	"github.com/nebula-contrib/ngctl/pkg/config"
-	"github.com/nebula-contrib/ngctl/pkg/util"
+	"github.com/nebula-contrib/ngctl/pkg/printer"
 )
 
 const (
@@ -46,29 +46,30 @@ func getCmd() *cobra.Command {
 		Short: "get component of nebula graph cluster",
 		Long:  "get component of nebula graph cluster.",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return get(args, allNamespaces)
+			return get(cmd.Context(), args, allNamespaces)
 		},
 	}
 	cmd.PersistentFlags().BoolVarP(&allNamespaces, "all-namespaces", "A", false, "if set, list the nebula graph clusters across all namespaces")
 	return cmd
 }
 
-func get(args []string, allNamespaces bool) error {
+func get(ctx context.Context, args []string, allNamespaces bool) error {
 	if len(args) == 0 {
 		return errors.New("please specify the kind of component")
 	}
 	kind := args[0]
-	ctx := context.Background()
-	client, err := util.NewClientSet(kubeConfig)
+	if err := printer.Validate(output); err != nil {
+		return err
+	}
+	name, namespace, err := selectCluster(ctx)
 	if err != nil {
 		return err
 	}
 
-	conf, err := config.LoadConfig()
+	client, err := newClientSet()
 	if err != nil {
 		return err
 	}
-	name, namespace := conf.Name, conf.Namespace
 
 	switch kind {
 	case graphd, metad, storaged:
@@ -80,57 +81,147 @@ func get(args []string, allNamespaces bool) error {
 	}
 }
 
-func getComponents(ctx context.Context, client *kubernetes.Clientset, kind, name, namespace string, allNamespace bool) error {
-	podList, err := getComponentPods(ctx, client, kind, name, namespace, allNamespace)
-	if err != nil {
-		return err
+// podList is the json and yaml output of the get command for components
+type podList struct {
+	Items []podSummary `json:"items"`
+
+	// pods are evaluated by the jsonpath, go-template and custom-columns formats
+	pods *corev1.PodList
+}
+
+// podSummary is the summary of a pod of a component
+type podSummary struct {
+	Name              string      `json:"name"`
+	Namespace         string      `json:"namespace"`
+	Component         string      `json:"component"`
+	Ready             bool        `json:"ready"`
+	Phase             string      `json:"phase"`
+	Memory            string      `json:"memory"`
+	CPU               string      `json:"cpu"`
+	Restarts          int32       `json:"restarts"`
+	CreationTimestamp metav1.Time `json:"creationTimestamp"`
+	HostIP            string      `json:"hostIP"`
+	PodIP             string      `json:"podIP"`
+	NodeName          string      `json:"nodeName"`
+}
+
+func newPodSummary(pod *corev1.Pod) podSummary {
+	summary := podSummary{
+		Name:              pod.Name,
+		Namespace:         pod.Namespace,
+		Component:         pod.Labels["app.kubernetes.io/component"],
+		Phase:             string(pod.Status.Phase),
+		CreationTimestamp: pod.CreationTimestamp,
+		HostIP:            pod.Status.HostIP,
+		PodIP:             pod.Status.PodIP,
+		NodeName:          pod.Spec.NodeName,
 	}
-	if len(podList.Items) == 0 {
-		return nil
+	if len(pod.Spec.Containers) > 0 {
+		requests := pod.Spec.Containers[0].Resources.Requests
+		summary.Memory, summary.CPU = requests.Memory().String(), requests.Cpu().String()
 	}
-	t := table.NewWriter()
-	t.SetOutputMirror(os.Stdout)
-	t.AppendHeader(table.Row{"NAME", "READY", "STATUS", "MEMORY", "CPU", "RESTARTS", "AGE", "NODE"})
-	for _, pod := range podList.Items {
-		t.AppendRow(table.Row{
+	if len(pod.Status.ContainerStatuses) > 0 {
+		summary.Ready = pod.Status.ContainerStatuses[0].Ready
+		summary.Restarts = pod.Status.ContainerStatuses[0].RestartCount
+	}
+	return summary
+}
+
+func (l *podList) Tables() []printer.Table {
+	t := printer.Table{
+		Header:      table.Row{"NAME", "READY", "STATUS", "MEMORY", "CPU", "RESTARTS", "AGE", "NODE", "IP", "NODE NAME"},
+		WideColumns: 2,
+	}
+	for _, pod := range l.Items {
+		t.Rows = append(t.Rows, table.Row{
 			pod.Name,
-			pod.Status.ContainerStatuses[0].Ready,
-			pod.Status.Phase,
-			pod.Spec.Containers[0].Resources.Requests.Memory().String(),
-			pod.Spec.Containers[0].Resources.Requests.Cpu().String(),
-			pod.Status.ContainerStatuses[0].RestartCount,
+			pod.Ready,
+			pod.Phase,
+			pod.Memory,
+			pod.CPU,
+			pod.Restarts,
 			// Age
-			time.Since(pod.CreationTimestamp.Time).String(),
+			duration.HumanDuration(time.Since(pod.CreationTimestamp.Time)),
 			//	HostIp
-			pod.Status.HostIP,
-		},
-		)
+			pod.HostIP,
+			pod.PodIP,
+			pod.NodeName,
+		})
 	}
-	t.Render()
-	return nil
+	return []printer.Table{t}
 }
 
-func getComponentPods(ctx context.Context, client *kubernetes.Clientset, kind, name string, namespace string, allNamespace bool) (*corev1.PodList, error) {
+func (l *podList) Names() []string {
+	names := make([]string, 0, len(l.Items))
+	for _, pod := range l.Items {
+		names = append(names, "pod/"+pod.Name)
+	}
+	return names
+}
+
+func (l *podList) Object() interface{} {
+	return l.pods
+}
+
+func (l *podList) Objects() []interface{} {
+	items := make([]interface{}, 0, len(l.pods.Items))
+	for i := range l.pods.Items {
+		items = append(items, &l.pods.Items[i])
+	}
+	return items
+}
+
+func getComponents(ctx context.Context, client kubernetes.Interface, kind, name, namespace string, allNamespace bool) error {
+	pods, err := getComponentPods(ctx, client, kind, name, namespace, allNamespace)
+	if err != nil {
+		return err
+	}
+	if len(pods.Items) == 0 && (output == printer.FormatTable || output == printer.FormatWide) {
+		return nil
+	}
+	result := &podList{Items: []podSummary{}, pods: pods}
+	for i := range pods.Items {
+		result.Items = append(result.Items, newPodSummary(&pods.Items[i]))
+	}
+	return printer.Print(stdout, output, result)
+}
+
+// getComponentPods lists the pods of a component ordered by namespace and name
+func getComponentPods(ctx context.Context, client kubernetes.Interface, kind, name string, namespace string, allNamespace bool) (*corev1.PodList, error) {
+	selector := componentSelector(kind, name)
 	if allNamespace {
-		return client.CoreV1().Pods("").List(ctx, metav1.ListOptions{
-			// ignore namespace, kind, name
-			LabelSelector: "app.kubernetes.io/name=nebula-graph,app.kubernetes.io/component",
-		})
+		// ignore namespace, kind, name
+		selector = "app.kubernetes.io/name=nebula-graph,app.kubernetes.io/component"
+		namespace = ""
 	}
-	var selector string
+
+	pods, err := client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: selector,
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(pods.Items, func(i, j int) bool {
+		if pods.Items[i].Namespace != pods.Items[j].Namespace {
+			return pods.Items[i].Namespace < pods.Items[j].Namespace
+		}
+		return pods.Items[i].Name < pods.Items[j].Name
+	})
+	return pods, nil
+}
+
+// componentSelector returns the label selector of the pods of a component of the nebula graph cluster name, the pods
+// of all components are selected if kind is not a component
+func componentSelector(kind, name string) string {
 	switch kind {
 	case graphd, metad, storaged:
-		selector = "app.kubernetes.io/cluster=" + name + ",app.kubernetes.io/component=" + kind + ",app.kubernetes.io/name=nebula-graph"
+		return "app.kubernetes.io/cluster=" + name + ",app.kubernetes.io/component=" + kind + ",app.kubernetes.io/name=nebula-graph"
 	default:
-		selector = "app.kubernetes.io/cluster=" + name + ",app.kubernetes.io/name=nebula-graph"
+		return "app.kubernetes.io/cluster=" + name + ",app.kubernetes.io/name=nebula-graph"
 	}
-
-	return client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
-		LabelSelector: selector,
-	})
 }
 
-func getPersistentVolume(ctx context.Context, client *kubernetes.Clientset, name string, namespace string, allNamespace bool) (*corev1.PersistentVolumeList, error) {
+func getPersistentVolume(ctx context.Context, client kubernetes.Interface, name string, namespace string, allNamespace bool) (*corev1.PersistentVolumeList, error) {
 	var selector string
 	if allNamespace {
 		selector = "app.kubernetes.io/name=nebula-graph"
@@ -150,11 +241,73 @@ func getPersistentVolume(ctx context.Context, client *kubernetes.Clientset, name
 			list.Items = append(list.Items, pv)
 		}
 	}
+	sort.Slice(list.Items, func(i, j int) bool {
+		return list.Items[i].Name < list.Items[j].Name
+	})
 
 	return &list, err
 }
 
-func getVolumes(ctx context.Context, client *kubernetes.Clientset, name string, namespace string, allNamespace bool) error {
+// volumeList is the json and yaml output of the get command for volumes
+type volumeList struct {
+	Items []volumeSummary `json:"items"`
+
+	// volumes are evaluated by the jsonpath, go-template and custom-columns formats
+	volumes *corev1.PersistentVolumeList
+}
+
+// volumeSummary is the summary of a persistent volume of a nebula graph cluster
+type volumeSummary struct {
+	Name          string `json:"name"`
+	Claim         string `json:"claim"`
+	Namespace     string `json:"namespace"`
+	Phase         string `json:"phase"`
+	Capacity      string `json:"capacity"`
+	HostIP        string `json:"hostIP"`
+	StorageClass  string `json:"storageClass"`
+	ReclaimPolicy string `json:"reclaimPolicy"`
+}
+
+func (l *volumeList) Tables() []printer.Table {
+	t := printer.Table{
+		Header:      table.Row{"VOLUME", "CLAIM", "STATUS", "CAPACITY", "HOST IP", "STORAGE CLASS", "RECLAIM POLICY"},
+		WideColumns: 2,
+	}
+	for _, volume := range l.Items {
+		t.Rows = append(t.Rows, table.Row{
+			volume.Name,
+			volume.Claim,
+			volume.Phase,
+			volume.Capacity,
+			volume.HostIP,
+			volume.StorageClass,
+			volume.ReclaimPolicy,
+		})
+	}
+	return []printer.Table{t}
+}
+
+func (l *volumeList) Names() []string {
+	names := make([]string, 0, len(l.Items))
+	for _, volume := range l.Items {
+		names = append(names, "persistentvolume/"+volume.Name)
+	}
+	return names
+}
+
+func (l *volumeList) Object() interface{} {
+	return l.volumes
+}
+
+func (l *volumeList) Objects() []interface{} {
+	items := make([]interface{}, 0, len(l.volumes.Items))
+	for i := range l.volumes.Items {
+		items = append(items, &l.volumes.Items[i])
+	}
+	return items
+}
+
+func getVolumes(ctx context.Context, client kubernetes.Interface, name string, namespace string, allNamespace bool) error {
 	pvs, err := getPersistentVolume(ctx, client, name, namespace, allNamespace)
 	if err != nil {
 		return err
@@ -169,31 +322,26 @@ func getVolumes(ctx context.Context, client *kubernetes.Clientset, name string,
 	for _, pod := range podList.Items {
 		for _, volume := range pod.Spec.Volumes {
 			if volume.PersistentVolumeClaim != nil {
-				key := fmt.Sprintf("%s/%s", namespace, volume.PersistentVolumeClaim.ClaimName)
+				key := fmt.Sprintf("%s/%s", pod.Namespace, volume.PersistentVolumeClaim.ClaimName)
 				// Node IP
 				podMap[key] = pod.Status.HostIP
 			}
 		}
 	}
 
-	t := table.NewWriter()
-	t.SetOutputMirror(os.Stdout)
-	t.AppendHeader(table.Row{"VOLUME", "CLAIM", "STATUS", "CAPACITY", "HOST IP"})
-
+	result := &volumeList{Items: []volumeSummary{}, volumes: pvs}
 	for _, pv := range pvs.Items {
-		t.AppendRow(table.Row{
-			pv.Name,
-			// Claim
-			pv.Spec.ClaimRef.Name,
-			// Status
-			pv.Status.Phase,
-			// Capacity
-			pv.Spec.Capacity.Storage().String(),
-			// Host IP
-			podMap[fmt.Sprintf("%s/%s", namespace, pv.Spec.ClaimRef.Name)],
+		claim := pv.Spec.ClaimRef
+		result.Items = append(result.Items, volumeSummary{
+			Name:          pv.Name,
+			Claim:         claim.Name,
+			Namespace:     claim.Namespace,
+			Phase:         string(pv.Status.Phase),
+			Capacity:      pv.Spec.Capacity.Storage().String(),
+			HostIP:        podMap[fmt.Sprintf("%s/%s", claim.Namespace, claim.Name)],
+			StorageClass:  pv.Spec.StorageClassName,
+			ReclaimPolicy: string(pv.Spec.PersistentVolumeReclaimPolicy),
 		})
 	}
-
-	t.Render()
-	return nil
+	return printer.Print(stdout, output, result)
 }
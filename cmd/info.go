@@ -17,24 +17,26 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
-	"os"
+	"sort"
 
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/spf13/cobra"
 	"github.com/vesoft-inc/nebula-operator/apis/apps/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/typed/core/v1"
 
-	"github.com/nebula-contrib/ngctl/pkg/config"
-	"github.com/nebula-contrib/ngctl/pkg/util"
+	"github.com/nebula-contrib/ngctl/pkg/cluster"
+	"github.com/nebula-contrib/ngctl/pkg/printer"
 )
 
-const serviceSelector = "app.kubernetes.io/cluster=%s,app.kubernetes.io/component=%s,app.kubernetes.io/name=nebula-graph"
+const (
+	serviceSelector = "app.kubernetes.io/cluster=%s,app.kubernetes.io/component=%s,app.kubernetes.io/name=nebula-graph"
+	// none is printed for the fields which are not set
+	none = "<none>"
+)
 
 func infoCmd() *cobra.Command {
 	cmd := &cobra.Command{
@@ -42,108 +44,203 @@ func infoCmd() *cobra.Command {
 		Short: "information of nebula graph clusters",
 		Long:  "information of nebula graph clusters.",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return info()
+			return info(cmd.Context())
 		},
 	}
 	return cmd
 }
 
-func info() error {
-	conf, err := config.LoadConfig()
+// clusterInfoResult is the json and yaml output of the info command
+type clusterInfoResult struct {
+	Cluster   clusterMeta         `json:"cluster"`
+	Overview  []componentOverview `json:"overview"`
+	Endpoints []endpoint          `json:"endpoints"`
+
+	// cluster and the endpoints are evaluated by the jsonpath, go-template and custom-columns formats
+	cluster *v1alpha1.NebulaCluster
+}
+
+// clusterInfoSource is the object evaluated by the jsonpath and go-template formats
+type clusterInfoSource struct {
+	Cluster   *v1alpha1.NebulaCluster `json:"cluster"`
+	Endpoints []endpoint              `json:"endpoints"`
+}
+
+// clusterMeta is the metadata of a nebula graph cluster
+type clusterMeta struct {
+	Name              string      `json:"name"`
+	Namespace         string      `json:"namespace"`
+	CreationTimestamp metav1.Time `json:"creationTimestamp"`
+}
+
+// componentOverview is the status and the spec of a component
+type componentOverview struct {
+	Component  string `json:"component"`
+	Phase      string `json:"phase"`
+	Ready      int32  `json:"ready"`
+	Desired    *int32 `json:"desired"`
+	CPU        string `json:"cpu"`
+	Memory     string `json:"memory"`
+	DataVolume string `json:"dataVolume"`
+	LogVolume  string `json:"logVolume"`
+	Version    string `json:"version"`
+	Image      string `json:"image"`
+}
+
+// endpoint is an address to access a component
+type endpoint struct {
+	Component string `json:"component"`
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	Endpoint  string `json:"endpoint"`
+}
+
+func (r *clusterInfoResult) Tables() []printer.Table {
+	return []printer.Table{
+		{
+			Rows: []table.Row{
+				{"Name", r.Cluster.Name},
+				{"Namespace", r.Cluster.Namespace},
+				{"CreationTimestamp", r.Cluster.CreationTimestamp},
+			},
+		},
+		overviewTable(r.Overview),
+		endpointsTable(r.Endpoints),
+	}
+}
+
+func (r *clusterInfoResult) Names() []string {
+	return []string{"nebulacluster/" + r.Cluster.Name}
+}
+
+func (r *clusterInfoResult) Object() interface{} {
+	return &clusterInfoSource{Cluster: r.cluster, Endpoints: r.Endpoints}
+}
+
+// Objects returns the endpoints, the rows of the custom-columns format
+func (r *clusterInfoResult) Objects() []interface{} {
+	items := make([]interface{}, 0, len(r.Endpoints))
+	for i := range r.Endpoints {
+		items = append(items, &r.Endpoints[i])
+	}
+	return items
+}
+
+func info(ctx context.Context) error {
+	if err := printer.Validate(output); err != nil {
+		return err
+	}
+	name, namespace, err := selectCluster(ctx)
 	if err != nil {
 		return err
 	}
-	namespace, name := conf.Namespace, conf.Name
 
-	client, err := util.NewDynamicClient(kubeConfig)
+	client, err := newDynamicClient()
 	if err != nil {
 		return err
 	}
 
-	ctx := context.Background()
-	cluster, err := getCluster(ctx, client, name, namespace)
+	nc, err := getCluster(ctx, client, name, namespace)
 	if err != nil {
 		return err
 	}
-	clusterInfo(cluster)
-	log.Println("Overview:")
-	componentInfo(cluster)
-	clientSet, err := util.NewClientSet(kubeConfig)
+	clientSet, err := newClientSet()
 	if err != nil {
 		return err
 	}
-	log.Println("Endpoints:")
-	err = endpointsInfo(ctx, clientSet, name, namespace)
+	endpoints, err := endpointsInfo(ctx, clientSet, name, namespace)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return printer.Print(stdout, output, &clusterInfoResult{
+		Cluster: clusterMeta{
+			Name:              nc.Name,
+			Namespace:         nc.Namespace,
+			CreationTimestamp: nc.CreationTimestamp,
+		},
+		Overview:  componentOverviews(nc),
+		Endpoints: endpoints,
+		cluster:   nc,
+	})
 }
 
-func endpointsInfo(ctx context.Context, clientSet *kubernetes.Clientset, name string, namespace string) error {
-	t := table.NewWriter()
-	t.SetOutputMirror(os.Stdout)
-	t.AppendHeader(table.Row{"Component", "Name", "Type", "Endpoint"})
+func endpointsInfo(ctx context.Context, clientSet kubernetes.Interface, name string, namespace string) ([]endpoint, error) {
 	coreV1 := clientSet.CoreV1()
 
-	err := nodePort(ctx, name, coreV1, namespace, t)
+	nodePorts, err := nodePort(ctx, name, coreV1, namespace)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	endpoints := append([]endpoint{}, nodePorts...)
 
 	for _, kind := range []string{"metad", "storaged", "graphd"} {
-		err = clusterIp(ctx, coreV1, t, name, namespace, kind)
+		clusterIPs, err := clusterIp(ctx, coreV1, name, namespace, kind)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		endpoints = append(endpoints, clusterIPs...)
+	}
+	return endpoints, nil
+}
+
+func endpointsTable(endpoints []endpoint) printer.Table {
+	t := printer.Table{
+		Title:  "Endpoints",
+		Header: table.Row{"Component", "Name", "Type", "Endpoint"},
+	}
+	for _, e := range endpoints {
+		t.Rows = append(t.Rows, table.Row{e.Component, e.Name, e.Type, e.Endpoint})
 	}
-	t.Render()
-	return nil
+	return t
 }
 
-func clusterIp(ctx context.Context, coreV1 v1.CoreV1Interface, t table.Writer, name, namespace, kind string) error {
+func clusterIp(ctx context.Context, coreV1 v1.CoreV1Interface, name, namespace, kind string) ([]endpoint, error) {
 	label := fmt.Sprintf(serviceSelector, name, kind)
 	svc, err := coreV1.Services(namespace).List(ctx, metav1.ListOptions{LabelSelector: label})
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if len(svc.Items) == 0 {
-		return errors.New("no service found")
+		return nil, errors.New("no service found")
 	}
 	service := svc.Items[0]
+	var endpoints []endpoint
 	for _, port := range service.Spec.Ports {
-		t.AppendRow(table.Row{
-			kind,
-			port.Name,
-			"ClusterIP",
-			fmt.Sprintf("%s.%s.svc.cluster.local:%d", service.Name, service.Namespace, port.Port),
+		endpoints = append(endpoints, endpoint{
+			Component: kind,
+			Name:      port.Name,
+			Type:      "ClusterIP",
+			Endpoint:  fmt.Sprintf("%s.%s.svc.cluster.local:%d", service.Name, service.Namespace, port.Port),
 		})
 	}
-	return nil
+	return endpoints, nil
 }
 
-func nodePort(ctx context.Context, name string, coreV1 v1.CoreV1Interface, namespace string, t table.Writer) error {
+func nodePort(ctx context.Context, name string, coreV1 v1.CoreV1Interface, namespace string) ([]endpoint, error) {
 	const kind = "graphd"
 	// query graphd service
 	label := fmt.Sprintf(serviceSelector, name, kind)
 	svc, err := coreV1.Services(namespace).List(ctx, metav1.ListOptions{LabelSelector: label})
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if len(svc.Items) == 0 {
-		return errors.New("no service found")
+		return nil, errors.New("no service found")
 	}
 	service := svc.Items[0]
 	if service.Spec.Type != corev1.ServiceTypeNodePort {
-		return nil // skip if not node port
+		return nil, nil // skip if not node port
 	}
 
 	// query ip of all nodes
 	list, err := coreV1.Nodes().List(ctx, metav1.ListOptions{})
 	if err != nil {
-		return err
+		return nil, err
 	}
+	sort.Slice(list.Items, func(i, j int) bool {
+		return list.Items[i].Name < list.Items[j].Name
+	})
 	var IPs []string
 	for _, node := range list.Items {
 		for _, addr := range node.Status.Addresses {
@@ -154,59 +251,121 @@ func nodePort(ctx context.Context, name string, coreV1 v1.CoreV1Interface, names
 	}
 
 	// construct node port endpoints
+	var endpoints []endpoint
 	for _, port := range service.Spec.Ports {
 		if port.NodePort < 0 {
 			continue
 		}
 		for _, ip := range IPs {
-			t.AppendRow(table.Row{
-				kind,
-				port.Name,
-				"NodePort",
-				fmt.Sprintf("%s:%d", ip, port.NodePort),
+			endpoints = append(endpoints, endpoint{
+				Component: kind,
+				Name:      port.Name,
+				Type:      "NodePort",
+				Endpoint:  fmt.Sprintf("%s:%d", ip, port.NodePort),
 			})
 		}
 	}
-	return nil
+	return endpoints, nil
 }
 
-func componentInfo(cluster *v1alpha1.NebulaCluster) {
-	t := table.NewWriter()
-	t.SetOutputMirror(os.Stdout)
-	t.AppendHeader(table.Row{"",
-		"Phase", "Ready", "Desired",
-		"CPU", "Memory", "DataVolume",
-		"LogVolume", "Version"})
-
-	status := cluster.Status
-	spec := cluster.Spec
+func componentOverviews(nc *v1alpha1.NebulaCluster) []componentOverview {
+	status := nc.Status
+	spec := nc.Spec
+	overviews := make([]componentOverview, 0, 3)
 	//	Metad
-	workload := status.Metad.Workload
-	metad := spec.Metad
-	t.AppendRow(table.Row{"Metad",
-		status.Metad.Phase, workload.ReadyReplicas, *metad.Replicas,
-		metad.Resources.Limits.Cpu(), metad.Resources.Limits.Memory(), metad.DataVolumeClaim.Resources.Requests.Storage(),
-		metad.LogVolumeClaim.Resources.Requests.Storage(), metad.Version})
-
-	workload = status.Storaged.Workload
-	storaged := spec.Storaged
-
-	// compute total storage of storaged
-	storagedStorage := computeStoragedVolume(storaged.DataVolumeClaims)
+	metad := newComponentOverview("Metad", status.Metad, nil, nil)
+	if spec.Metad != nil {
+		metad = newComponentOverview("Metad", status.Metad, &spec.Metad.ComponentSpec, spec.Metad.LogVolumeClaim)
+		metad.DataVolume = claimStorage(spec.Metad.DataVolumeClaim)
+	}
+	overviews = append(overviews, metad)
+
 	// Storaged
-	t.AppendRow(table.Row{"Storaged",
-		status.Storaged.Phase, workload.ReadyReplicas, *storaged.Replicas,
-		storaged.Resources.Limits.Cpu(), storaged.Resources.Limits.Memory(), storagedStorage,
-		storaged.LogVolumeClaim.Resources.Requests.Storage(), storaged.Version})
+	storaged := newComponentOverview("Storaged", status.Storaged.ComponentStatus, nil, nil)
+	if spec.Storaged != nil {
+		storaged = newComponentOverview("Storaged", status.Storaged.ComponentStatus, &spec.Storaged.ComponentSpec,
+			spec.Storaged.LogVolumeClaim)
+		// compute total storage of storaged
+		storaged.DataVolume = computeStoragedVolume(spec.Storaged.DataVolumeClaims)
+	}
+	overviews = append(overviews, storaged)
 
 	// Graphd
-	workload = status.Graphd.Workload
-	graphd := spec.Graphd
-	t.AppendRow(table.Row{"Graphd",
-		status.Graphd.Phase, workload.ReadyReplicas, *graphd.Replicas,
-		graphd.Resources.Limits.Cpu(), graphd.Resources.Limits.Memory(), "",
-		graphd.LogVolumeClaim.Resources.Requests.Storage(), graphd.Version})
-	t.Render()
+	graphd := newComponentOverview("Graphd", status.Graphd, nil, nil)
+	if spec.Graphd != nil {
+		graphd = newComponentOverview("Graphd", status.Graphd, &spec.Graphd.ComponentSpec, spec.Graphd.LogVolumeClaim)
+	}
+	graphd.DataVolume = ""
+	return append(overviews, graphd)
+}
+
+// newComponentOverview returns the overview of a component, the fields which are not set in the spec are <none>
+func newComponentOverview(component string, status v1alpha1.ComponentStatus, spec *v1alpha1.ComponentSpec,
+	logVolumeClaim *v1alpha1.StorageClaim) componentOverview {
+	overview := componentOverview{
+		Component:  component,
+		Phase:      string(status.Phase),
+		Ready:      status.Workload.ReadyReplicas,
+		CPU:        none,
+		Memory:     none,
+		DataVolume: none,
+		LogVolume:  claimStorage(logVolumeClaim),
+	}
+	if spec == nil {
+		return overview
+	}
+	overview.Desired = spec.Replicas
+	overview.Version, overview.Image = spec.Version, spec.Image
+	if spec.Resources != nil {
+		overview.CPU = quantityString(spec.Resources.Limits, corev1.ResourceCPU)
+		overview.Memory = quantityString(spec.Resources.Limits, corev1.ResourceMemory)
+	}
+	return overview
+}
+
+// claimStorage returns the requested storage of a volume claim, <none> is returned if it is not set
+func claimStorage(claim *v1alpha1.StorageClaim) string {
+	if claim == nil {
+		return none
+	}
+	return quantityString(claim.Resources.Requests, corev1.ResourceStorage)
+}
+
+func quantityString(resources corev1.ResourceList, name corev1.ResourceName) string {
+	quantity, ok := resources[name]
+	if !ok {
+		return none
+	}
+	return quantity.String()
+}
+
+func overviewTable(overviews []componentOverview) printer.Table {
+	t := printer.Table{
+		Title: "Overview",
+		Header: table.Row{"",
+			"Phase", "Ready", "Desired",
+			"CPU", "Memory", "DataVolume",
+			"LogVolume", "Version", "Image"},
+		WideColumns: 1,
+	}
+	for _, o := range overviews {
+		desired := interface{}(none)
+		if o.Desired != nil {
+			desired = *o.Desired
+		}
+		t.Rows = append(t.Rows, table.Row{o.Component,
+			o.Phase, o.Ready, desired,
+			o.CPU, o.Memory, o.DataVolume,
+			o.LogVolume, o.Version, o.Image})
+	}
+	return t
+}
+
+// componentInfo prints the overview table of the components
+func componentInfo(nc *v1alpha1.NebulaCluster) {
+	overview := overviewTable(componentOverviews(nc))
+	overview.Title = ""
+	printer.Render(stdout, overview, false)
 }
 
 func computeStoragedVolume(volumeClaims []v1alpha1.StorageClaim) string {
@@ -218,32 +377,11 @@ func computeStoragedVolume(volumeClaims []v1alpha1.StorageClaim) string {
 		}
 		storagedStorage = totalStorage.String()
 	} else {
-		storagedStorage = ""
+		storagedStorage = none
 	}
 	return storagedStorage
 }
 
-func clusterInfo(cluster *v1alpha1.NebulaCluster) {
-	t := table.NewWriter()
-	t.SetOutputMirror(os.Stdout)
-
-	t.AppendRow(table.Row{"Name", cluster.Name})
-	t.AppendRow(table.Row{"Namespace", cluster.Namespace})
-	t.AppendRow(table.Row{"CreationTimestamp", cluster.CreationTimestamp})
-	t.Render()
-}
-
-func getCluster(ctx context.Context, client *dynamic.DynamicClient, name, namespace string) (*v1alpha1.NebulaCluster, error) {
-	resource := v1alpha1.GroupVersion.WithResource("nebulaclusters")
-	r, err := client.Resource(resource).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
-	if err != nil {
-		return nil, err
-	}
-
-	cluster := &v1alpha1.NebulaCluster{}
-	err = runtime.DefaultUnstructuredConverter.FromUnstructured(r.Object, cluster)
-	if err != nil {
-		return nil, err
-	}
-	return cluster, nil
+func getCluster(ctx context.Context, client dynamic.Interface, name, namespace string) (*v1alpha1.NebulaCluster, error) {
+	return cluster.Get(ctx, client, name, namespace)
 }
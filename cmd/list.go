@@ -16,59 +16,144 @@ package cmd
 import (
 	"context"
 	"fmt"
-	"log"
-	"os"
+	"time"
 
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/spf13/cobra"
+	"github.com/vesoft-inc/nebula-operator/apis/apps/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
 
+	"github.com/nebula-contrib/ngctl/pkg/cluster"
 	"github.com/nebula-contrib/ngctl/pkg/list"
-	"github.com/nebula-contrib/ngctl/pkg/util"
+	"github.com/nebula-contrib/ngctl/pkg/logger"
+	"github.com/nebula-contrib/ngctl/pkg/printer"
 )
 
 func listCmd() *cobra.Command {
 	var (
-		namespace     string
 		allNamespaces bool
 	)
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "list all installed nebula graph clusters",
 		Long:  "list all installed nebula graph clusters.",
+		Example: `  # list the nebula graph clusters across all namespaces with their versions and ages
+  ngctl list -A -o wide
+  # output the summaries of the nebula graph clusters in json
+  ngctl list -o json
+`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return listClusters(allNamespaces, namespace)
+			return listClusters(cmd.Context(), allNamespaces, namespaceOrDefault())
 		},
 	}
-	cmd.PersistentFlags().StringVar(&namespace, "namespace", "default", "namespace of the nebula graph cluster")
 	cmd.PersistentFlags().BoolVarP(&allNamespaces, "all-namespaces", "A", false, "if set, list the nebula graph clusters across all namespaces")
 	return cmd
 }
 
-func listClusters(allNamespaces bool, namespace string) error {
-	client, err := util.NewDynamicClient(kubeConfig)
+// clusterList is the json and yaml output of the list command
+type clusterList struct {
+	Items []clusterSummary `json:"items"`
+
+	// clusters are evaluated by the jsonpath, go-template and custom-columns formats
+	clusters []v1alpha1.NebulaCluster
+}
+
+// clusterSummary is the summary of a nebula graph cluster
+type clusterSummary struct {
+	Name              string           `json:"name"`
+	Namespace         string           `json:"namespace"`
+	CreationTimestamp metav1.Time      `json:"creationTimestamp"`
+	Graphd            componentSummary `json:"graphd"`
+	Metad             componentSummary `json:"metad"`
+	Storaged          componentSummary `json:"storaged"`
+}
+
+// componentSummary is the summary of a component of a nebula graph cluster
+type componentSummary struct {
+	Phase   string `json:"phase"`
+	Ready   int32  `json:"ready"`
+	Desired int32  `json:"desired"`
+	Version string `json:"version"`
+}
+
+func newClusterSummary(nc *v1alpha1.NebulaCluster) clusterSummary {
+	return clusterSummary{
+		Name:              nc.Name,
+		Namespace:         nc.Namespace,
+		CreationTimestamp: nc.CreationTimestamp,
+		Graphd:            newComponentSummary(nc, cluster.Graphd),
+		Metad:             newComponentSummary(nc, cluster.Metad),
+		Storaged:          newComponentSummary(nc, cluster.Storaged),
+	}
+}
+
+func newComponentSummary(nc *v1alpha1.NebulaCluster, component string) componentSummary {
+	status, desired := cluster.ComponentStatus(nc, component)
+	return componentSummary{
+		Phase:   string(status.Phase),
+		Ready:   status.Workload.ReadyReplicas,
+		Desired: desired,
+		Version: cluster.ComponentVersion(nc, component),
+	}
+}
+
+func (s componentSummary) ready() string {
+	return fmt.Sprintf("%d/%d", s.Ready, s.Desired)
+}
+
+func (l *clusterList) Tables() []printer.Table {
+	t := printer.Table{
+		Header:      table.Row{"Namespace", "Name", "Graphd", "Metad", "Storaged", "Version", "Age"},
+		WideColumns: 2,
+	}
+	for _, item := range l.Items {
+		t.Rows = append(t.Rows, table.Row{item.Namespace, item.Name,
+			item.Graphd.ready(), item.Metad.ready(), item.Storaged.ready(),
+			item.Graphd.Version, duration.HumanDuration(time.Since(item.CreationTimestamp.Time))})
+	}
+	return []printer.Table{t}
+}
+
+func (l *clusterList) Names() []string {
+	names := make([]string, 0, len(l.Items))
+	for _, item := range l.Items {
+		names = append(names, "nebulacluster/"+item.Name)
+	}
+	return names
+}
+
+func (l *clusterList) Object() interface{} {
+	return &v1alpha1.NebulaClusterList{Items: l.clusters}
+}
+
+func (l *clusterList) Objects() []interface{} {
+	items := make([]interface{}, 0, len(l.clusters))
+	for i := range l.clusters {
+		items = append(items, &l.clusters[i])
+	}
+	return items
+}
+
+func listClusters(ctx context.Context, allNamespaces bool, namespace string) error {
+	if err := printer.Validate(output); err != nil {
+		return err
+	}
+	client, err := newDynamicClient()
 	if err != nil {
 		return err
 	}
-	ctx := context.Background()
 	clusters, err := list.Clusters(ctx, client, allNamespaces, namespace)
 	if err != nil {
 		return err
 	}
-	if len(clusters) == 0 {
-		log.Printf("no nebula graph cluster found in namespace %s", namespace)
+	if len(clusters) == 0 && (output == printer.FormatTable || output == printer.FormatWide) {
+		logger.Infof("no nebula graph cluster found in namespace %s", namespace)
 		return nil
 	}
-	t := table.NewWriter()
-	t.SetOutputMirror(os.Stdout)
-	t.AppendHeader(table.Row{"Namespace", "Name", "Graphd", "Metad", "Storaged"})
-	for _, cluster := range clusters {
-		status := cluster.Status
-		spec := cluster.Spec
-		graphdReady := fmt.Sprintf("%d/%d", status.Graphd.Workload.ReadyReplicas, *spec.Graphd.Replicas)
-		metadReady := fmt.Sprintf("%d/%d", status.Metad.Workload.ReadyReplicas, *spec.Metad.Replicas)
-		storagedReady := fmt.Sprintf("%d/%d", status.Storaged.Workload.ReadyReplicas, *spec.Storaged.Replicas)
-		t.AppendRow(table.Row{cluster.Namespace, cluster.Name, graphdReady, metadReady, storagedReady})
+	result := &clusterList{Items: []clusterSummary{}, clusters: clusters}
+	for i := range clusters {
+		result.Items = append(result.Items, newClusterSummary(&clusters[i]))
 	}
-	t.Render()
-	return nil
+	return printer.Print(stdout, output, result)
 }
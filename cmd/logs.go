@@ -0,0 +1,133 @@
+/*
+ * Copyright (c) 2023 The nebula-contrib Authors.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *     http://www.apache.org/licenses/LICENSE-2.
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/docker/cli/cli/streams"
+	"github.com/spf13/cobra"
+
+	"github.com/nebula-contrib/ngctl/pkg/glog"
+	"github.com/nebula-contrib/ngctl/pkg/logs"
+	"github.com/nebula-contrib/ngctl/pkg/printer"
+)
+
+const allComponents = "all"
+
+const (
+	colorAuto   = "auto"
+	colorAlways = "always"
+	colorNever  = "never"
+)
+
+func logsCmd() *cobra.Command {
+	var (
+		options logs.Options
+		grep    string
+		color   string
+		levels  []string
+	)
+	cmd := &cobra.Command{
+		Use:   "logs graphd|metad|storaged|all",
+		Short: "print the logs of the pods of a component",
+		Long: `print the logs of all pods of a component of the nebula graph cluster concurrently, each line is prefixed with its pod.
+with --follow, the pods created or restarted during a rollout are followed as they start.`,
+		Example: `  # print the last 100 lines of each graphd pod
+  ngctl logs graphd --tail 100
+  # follow the logs of all components in the last 10 minutes
+  ngctl logs all -f --since 10m
+  # print the errors of the previous storaged containers
+  ngctl logs storaged --previous --grep "^E"
+  # print the errors and the warnings of metad as json objects
+  ngctl logs metad --level E,W -o json | jq .message
+`,
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: []string{graphd, metad, storaged, allComponents},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if grep != "" {
+				expr, err := regexp.Compile(grep)
+				if err != nil {
+					return fmt.Errorf("invalid --grep %s: %w", grep, err)
+				}
+				options.Grep = expr
+			}
+			for _, level := range levels {
+				severity, err := glog.ParseSeverity(level)
+				if err != nil {
+					return err
+				}
+				options.Levels = append(options.Levels, severity)
+			}
+			switch output {
+			case printer.FormatTable:
+			case printer.FormatJSON:
+				options.JSON = true
+			default:
+				return fmt.Errorf("unsupported output format %s of logs, only json is supported", output)
+			}
+			switch color {
+			case colorAuto:
+				options.Color = streams.NewOut(stdout).IsTerminal() && os.Getenv("NO_COLOR") == ""
+			case colorAlways, colorNever:
+				options.Color = color == colorAlways
+			default:
+				return fmt.Errorf("unsupported color mode %s, one of auto|always|never", color)
+			}
+			return printLogs(cmd.Context(), args[0], options)
+		},
+	}
+	flags := cmd.PersistentFlags()
+	flags.BoolVarP(&options.Follow, "follow", "f", false, "if set, follow the logs until interrupted")
+	flags.DurationVar(&options.Since, "since", 0, "only print the logs newer than a duration such as 5s, 2m or 3h, zero means all logs")
+	flags.Int64Var(&options.Tail, "tail", -1, "lines of the recent logs of each pod, -1 means all lines")
+	flags.BoolVarP(&options.Previous, "previous", "p", false, "if set, print the logs of the previous terminated containers")
+	flags.StringVar(&grep, "grep", "", "only print the lines matching the regular expression")
+	flags.StringSliceVar(&levels, "level", nil, "only print the glog records of the severities, such as E,W, the stack traces follow their records")
+	flags.StringVar(&color, "color", colorAuto, "color the pod names, one of auto|always|never, auto colors them in a terminal")
+	return cmd
+}
+
+func printLogs(ctx context.Context, kind string, options logs.Options) error {
+	switch kind {
+	case graphd, metad, storaged, allComponents:
+	default:
+		return fmt.Errorf("unsupported component %s, one of graphd|metad|storaged|all", kind)
+	}
+	name, namespace, err := selectCluster(ctx)
+	if err != nil {
+		return err
+	}
+	client, err := newClientSet()
+	if err != nil {
+		return err
+	}
+
+	aggregator := &logs.Aggregator{
+		Client:    client,
+		Namespace: namespace,
+		Selector:  componentSelector(kind, name),
+		Options:   options,
+		Out:       stdout,
+	}
+	err = aggregator.Run(ctx)
+	if errors.Is(err, logs.ErrNoPods) {
+		return fmt.Errorf("no %s pods of nebula graph cluster %s are found in namespace %s", kind, name, namespace)
+	}
+	return err
+}
@@ -0,0 +1,197 @@
+/*
+ * Copyright (c) 2023 The nebula-contrib Authors.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *     http://www.apache.org/licenses/LICENSE-2.
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/nebula-contrib/ngctl/pkg/logger"
+	"github.com/nebula-contrib/ngctl/pkg/portforward"
+)
+
+const studioTarget = "studio"
+
+func portForwardCmd() *cobra.Command {
+	var (
+		address    string
+		studioName string
+	)
+	cmd := &cobra.Command{
+		Use:   "port-forward graphd|COMPONENT-ORDINAL|studio [PORT_NAME...]",
+		Short: "forward the ports of nebula graph or studio to local ports",
+		Long: `forward the ports of a component of the nebula graph cluster or nebula graph studio to local ports until interrupted.
+the ports are named after the ports of the services such as thrift and http, all ports are forwarded if no port name is given.
+a component without ordinal such as graphd is forwarded to a running pod of its service. the local ports are the same as the
+remote ones if they are free, otherwise free ports are picked.`,
+		Example: `  # forward thrift 9669 and http 19669 of graphd
+  ngctl port-forward graphd
+  # forward the http port of metad-0
+  ngctl port-forward metad-0 http
+  # forward nebula graph studio
+  ngctl port-forward studio
+`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return portForward(cmd.Context(), args[0], args[1:], address, studioName)
+		},
+	}
+	cmd.Flags().StringVar(&address, "address", portforward.DefaultAddress, "local address to listen on")
+	cmd.Flags().StringVar(&studioName, "studio-name", "studio", "name of the nebula graph studio")
+	return cmd
+}
+
+func portForward(ctx context.Context, target string, portNames []string, address, studioName string) error {
+	var (
+		name      string
+		namespace = namespaceOrDefault()
+		err       error
+	)
+	if target != studioTarget {
+		// the cluster is selected before the clients are created so that they are bound to its kubernetes context
+		if name, namespace, err = selectCluster(ctx); err != nil {
+			return err
+		}
+	}
+	clientSet, err := newClientSet()
+	if err != nil {
+		return err
+	}
+	var (
+		service *corev1.Service
+		pod     *corev1.Pod
+	)
+	if target == studioTarget {
+		if service, err = studioService(ctx, clientSet, studioName, namespace); err != nil {
+			return err
+		}
+		if pod, err = portforward.ServicePod(ctx, clientSet, service); err != nil {
+			return err
+		}
+	} else if service, pod, err = componentTarget(ctx, clientSet, target, name, namespace); err != nil {
+		return err
+	}
+
+	ports, err := forwardedPorts(service, pod, portNames)
+	if err != nil {
+		return err
+	}
+	conf, err := newRESTConfig()
+	if err != nil {
+		return err
+	}
+	session, err := portforward.Forward(ctx, conf, clientSet, pod.Namespace, pod.Name, address, ports)
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	for _, endpoint := range session.Endpoints {
+		fmt.Fprintf(stdout, "forwarding %s from %s to %s:%d\n", endpoint.Name, endpoint.Local, endpoint.Pod, endpoint.Remote)
+	}
+	logger.Infof("press Ctrl-C to stop forwarding")
+	return session.Wait()
+}
+
+// componentTarget returns the service and the pod of a component addressed by target such as graphd or metad-0, the
+// pod of a component without ordinal is a running pod of its service
+func componentTarget(ctx context.Context, clientSet kubernetes.Interface, target, name, namespace string) (*corev1.Service, *corev1.Pod, error) {
+	component, ordinal, err := parsePodTarget(target)
+	if err != nil {
+		return nil, nil, err
+	}
+	service, err := componentService(ctx, clientSet, name, namespace, component)
+	if err != nil {
+		return nil, nil, err
+	}
+	var pod *corev1.Pod
+	if ordinal < 0 {
+		pod, err = portforward.ServicePod(ctx, clientSet, service)
+	} else {
+		pod, _, err = resolvePod(ctx, clientSet, target, name, namespace)
+	}
+	return service, pod, err
+}
+
+// componentService returns the service of a component the way clusterIp does, the service with a cluster ip is
+// preferred over the headless one
+func componentService(ctx context.Context, clientSet kubernetes.Interface, name, namespace, component string) (*corev1.Service, error) {
+	label := fmt.Sprintf(serviceSelector, name, component)
+	services, err := clientSet.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{LabelSelector: label})
+	if err != nil {
+		return nil, err
+	}
+	if len(services.Items) == 0 {
+		return nil, fmt.Errorf("no %s service of nebula graph cluster %s is found in namespace %s", component, name, namespace)
+	}
+	sort.SliceStable(services.Items, func(i, j int) bool {
+		iHeadless := services.Items[i].Spec.ClusterIP == corev1.ClusterIPNone
+		jHeadless := services.Items[j].Spec.ClusterIP == corev1.ClusterIPNone
+		if iHeadless != jHeadless {
+			return jHeadless
+		}
+		return services.Items[i].Name < services.Items[j].Name
+	})
+	return &services.Items[0], nil
+}
+
+// studioService returns the service of nebula graph studio installed by ngctl studio install
+func studioService(ctx context.Context, clientSet kubernetes.Interface, name, namespace string) (*corev1.Service, error) {
+	service, err := clientSet.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) || (err == nil && service.Labels[studioLabelKey] != name) {
+		return nil, fmt.Errorf("nebula graph studio %s is not found in namespace %s, install it by ngctl studio install", name, namespace)
+	}
+	return service, err
+}
+
+// forwardedPorts returns the ports of pod routed from the ports of service named portNames, all ports are returned if
+// portNames is empty
+func forwardedPorts(service *corev1.Service, pod *corev1.Pod, portNames []string) ([]portforward.Port, error) {
+	selected := service.Spec.Ports
+	if len(portNames) > 0 {
+		selected = nil
+		for _, portName := range portNames {
+			found := false
+			for _, port := range service.Spec.Ports {
+				if port.Name == portName {
+					selected = append(selected, port)
+					found = true
+				}
+			}
+			if !found {
+				names := make([]string, 0, len(service.Spec.Ports))
+				for _, port := range service.Spec.Ports {
+					names = append(names, port.Name)
+				}
+				return nil, fmt.Errorf("port %s is not found, the ports of %s are %s", portName, service.Name, strings.Join(names, ", "))
+			}
+		}
+	}
+	ports := make([]portforward.Port, 0, len(selected))
+	for _, port := range selected {
+		remote, err := portforward.TargetPort(pod, port)
+		if err != nil {
+			return nil, err
+		}
+		ports = append(ports, portforward.Port{Name: port.Name, Remote: remote})
+	}
+	return ports, nil
+}
@@ -14,30 +14,166 @@
 package cmd
 
 import (
-	"path/filepath"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
-	"k8s.io/client-go/util/homedir"
+	utilexec "k8s.io/client-go/util/exec"
+
+	"github.com/nebula-contrib/ngctl/pkg/logger"
 )
 
 // Flag Values for RootCmd
 var (
-	kubeConfig string
+	kubeConfig        string
+	kubeContext       string
+	kubeUser          string
+	impersonate       string
+	impersonateGroups []string
+	output            string
+	clusterName       string
+	clusterNamespace  string
+	requestTimeout    time.Duration
+	commandTimeout    time.Duration
+	verbosity         int
+	quiet             bool
+	logFormat         string
+	dryRun            string
+	qps               float32
+	burst             int
+)
+
+// stdin, stdout and stderr are the input and the outputs of the current command, they are replaced by SetIn, SetOut
+// and SetErr of the root command, such as in tests
+var (
+	stdin  io.Reader = os.Stdin
+	stdout io.Writer = os.Stdout
+	stderr io.Writer = os.Stderr
 )
 
-var RootCmd = &cobra.Command{
-	Use:   "ngctl [command]",
-	Short: "the command line tool for nebula operator",
-	Long:  "ngctl is the command line tool for nebula operator.",
+// cancelTimeout releases the context of --timeout
+var cancelTimeout context.CancelFunc = func() {}
+
+var RootCmd = NewRootCmd()
+
+// Execute runs RootCmd with a context which is canceled on SIGINT and SIGTERM, a second signal terminates ngctl
+// immediately
+func Execute() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	defer func() {
+		cancelTimeout()
+	}()
+
+	err := RootCmd.ExecuteContext(ctx)
+	switch {
+	case err == nil:
+	case ctx.Err() != nil:
+		err = fmt.Errorf("interrupted: %w", err)
+	case commandTimeout > 0 && errors.Is(err, context.DeadlineExceeded):
+		err = fmt.Errorf("timed out after %s: %w", commandTimeout, err)
+	}
+	return err
+}
+
+// ExitCode returns the exit code of ngctl for the error returned by Execute, the exit code of the command executed in
+// a pod and the ones of diff are passed through, the other errors exit with 1
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr utilexec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitStatus()
+	}
+	return 1
+}
+
+// waitTimeout returns the timeout of waiting for the nebula graph cluster, it is --timeout if set or the default
+// of the command
+func waitTimeout(defaultTimeout time.Duration) time.Duration {
+	if commandTimeout > 0 {
+		return commandTimeout
+	}
+	return defaultTimeout
 }
 
-func init() {
-	RootCmd.PersistentFlags().StringVar(&kubeConfig, "kubeconfig", filepath.Join(homedir.HomeDir(), ".kube", "config"), "path of the kubernetes config file")
-	RootCmd.AddCommand(studioCmd())
-	RootCmd.AddCommand(versionCmd())
-	RootCmd.AddCommand(listCmd())
-	RootCmd.AddCommand(useCmd())
-	RootCmd.AddCommand(infoCmd())
-	RootCmd.AddCommand(getCmd())
-	RootCmd.AddCommand(consoleCmd())
+// NewRootCmd returns a new ngctl command, the flag values are reset to their defaults,
+// the output of the commands is written to the writer set by SetOut
+func NewRootCmd() *cobra.Command {
+	root := &cobra.Command{
+		Use:   "ngctl [command]",
+		Short: "the command line tool for nebula operator",
+		Long:  "ngctl is the command line tool for nebula operator.",
+		// the error is logged by main in the log format
+		SilenceErrors: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			boundContext, currentFactory = nil, nil
+			stdin, stdout, stderr = cmd.InOrStdin(), cmd.OutOrStdout(), cmd.ErrOrStderr()
+			if err := logger.Configure(cmd.ErrOrStderr(), verbosity, quiet, logFormat); err != nil {
+				return err
+			}
+			if err := checkDryRun(cmd); err != nil {
+				return err
+			}
+			if commandTimeout > 0 {
+				ctx, cancel := context.WithTimeout(cmd.Context(), commandTimeout)
+				cmd.SetContext(ctx)
+				cancelTimeout = cancel
+			}
+			return nil
+		},
+	}
+	root.PersistentFlags().StringVar(&kubeConfig, "kubeconfig", "", "path of the kubernetes config file, the files in KUBECONFIG or ~/.kube/config are used if empty")
+	root.PersistentFlags().StringVar(&kubeContext, "context", "", "name of the kubernetes context to use")
+	root.PersistentFlags().StringVar(&kubeUser, "user", "", "name of the kubeconfig user to use")
+	root.PersistentFlags().StringVar(&impersonate, "as", "", "username to impersonate for the operation")
+	root.PersistentFlags().StringArrayVar(&impersonateGroups, "as-group", nil, "group to impersonate for the operation, this flag can be repeated")
+	root.PersistentFlags().DurationVar(&requestTimeout, "request-timeout", 0, "timeout of a single request to kubernetes, such as 30s, zero means no timeout")
+	root.PersistentFlags().DurationVar(&commandTimeout, "timeout", 0, "timeout of the command, such as 5m, zero means no timeout, "+
+		"the commands waiting for the nebula graph cluster use their own default")
+	root.PersistentFlags().Float32Var(&qps, "qps", 50, "maximum queries per second to kubernetes")
+	root.PersistentFlags().IntVar(&burst, "burst", 100, "maximum burst of queries to kubernetes")
+	root.PersistentFlags().StringVarP(&clusterName, "cluster", "c", "", "name of the nebula graph cluster, it overrides "+EnvCluster+" and the context in use")
+	root.PersistentFlags().StringVarP(&clusterNamespace, "namespace", "n", "", "namespace of the nebula graph cluster, it overrides "+EnvNamespace+" and the context in use")
+	root.PersistentFlags().StringVarP(&output, "output", "o", "", "output format, one of wide|json|yaml|name|jsonpath=...|go-template=...|custom-columns=..., print tables if empty")
+	root.PersistentFlags().IntVarP(&verbosity, "v", "v", 0, fmt.Sprintf("log verbosity between 0 and %d, the requests to kubernetes are logged from 6", logger.MaxVerbosity))
+	root.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "if set, only log the warnings and the errors")
+	root.PersistentFlags().StringVar(&logFormat, "log-format", logger.FormatText, "format of the logs written to stderr, one of "+strings.Join(logger.Formats, "|"))
+	root.PersistentFlags().StringVar(&dryRun, "dry-run", dryRunNone, "if client, print the manifests instead of creating them, "+
+		"if server, submit them with server dry run without persisting them, one of none|client|server")
+	root.AddCommand(studioCmd())
+	root.AddCommand(versionCmd())
+	root.AddCommand(listCmd())
+	root.AddCommand(useCmd())
+	root.AddCommand(contextCmd())
+	root.AddCommand(infoCmd())
+	root.AddCommand(getCmd())
+	root.AddCommand(consoleCmd())
+	root.AddCommand(createCmd())
+	root.AddCommand(deleteCmd())
+	root.AddCommand(scaleCmd())
+	root.AddCommand(upgradeCmd())
+	root.AddCommand(applyCmd())
+	root.AddCommand(diffCmd())
+	root.AddCommand(editCmd())
+	root.AddCommand(validateCmd())
+	root.AddCommand(templateCmd())
+	root.AddCommand(logsCmd())
+	root.AddCommand(execCmd())
+	root.AddCommand(shellCmd())
+	root.AddCommand(portForwardCmd())
+	root.AddCommand(describeCmd())
+	return root
 }
@@ -0,0 +1,141 @@
+/*
+ * Copyright (c) 2023 The nebula-contrib Authors.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *     http://www.apache.org/licenses/LICENSE-2.
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+	"github.com/vesoft-inc/nebula-operator/apis/apps/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/nebula-contrib/ngctl/pkg/cluster"
+	"github.com/nebula-contrib/ngctl/pkg/logger"
+)
+
+func scaleCmd() *cobra.Command {
+	var (
+		replicas int32
+	)
+	cmd := &cobra.Command{
+		Use:   "scale graphd|metad|storaged",
+		Short: "scale a component of nebula graph cluster",
+		Long:  "scale a component of nebula graph cluster and wait until the replicas are ready.",
+		Example: `  # scale storaged to 5 replicas
+  ngctl scale storaged --replicas 5
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return errors.New("please specify the kind of component")
+			}
+			if !cmd.Flags().Changed("replicas") {
+				return errors.New("please specify the replicas by --replicas")
+			}
+			return scale(cmd.Context(), args[0], replicas)
+		},
+	}
+	cmd.PersistentFlags().Int32Var(&replicas, "replicas", 0, "desired replicas of the component")
+	return cmd
+}
+
+func scale(ctx context.Context, kind string, replicas int32) error {
+	switch kind {
+	case graphd, metad, storaged:
+	default:
+		return errors.New("unsupported kind type of scale command")
+	}
+	if replicas < 0 {
+		return errors.New("replicas can not be negative")
+	}
+
+	name, namespace, err := selectCluster(ctx)
+	if err != nil {
+		return err
+	}
+
+	client, err := newDynamicClient()
+	if err != nil {
+		return err
+	}
+	clientSet, err := newClientSet()
+	if err != nil {
+		return err
+	}
+
+	patch := []byte(fmt.Sprintf(`{"spec":{"%s":{"replicas":%d}}}`, kind, replicas))
+	resource := v1alpha1.GroupVersion.WithResource("nebulaclusters")
+	_, err = client.Resource(resource).Namespace(namespace).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return err
+	}
+	logger.Infof("scale %s of nebula graph cluster %s to %d replicas", kind, name, replicas)
+
+	waitCtx, cancel := context.WithTimeout(ctx, waitTimeout(10*time.Minute))
+	defer cancel()
+	var last string
+	err = cluster.Wait(waitCtx, client, name, namespace, func(nc *v1alpha1.NebulaCluster) bool {
+		return nc.Status.ObservedGeneration >= nc.Generation && cluster.ComponentReady(nc, kind)
+	}, func(nc *v1alpha1.NebulaCluster) {
+		status, desired := cluster.ComponentStatus(nc, kind)
+		progress := fmt.Sprintf("%s %d/%d %s", kind, status.Workload.ReadyReplicas, desired, status.Phase)
+		if progress != last {
+			logger.Infof("%s", progress)
+			last = progress
+		}
+	})
+	if err != nil {
+		// ctx may be done by --timeout or a signal
+		reportCtx, cancelReport := context.WithTimeout(context.Background(), cleanupTimeout)
+		defer cancelReport()
+		if reportErr := reportStuckPods(reportCtx, clientSet, kind, name, namespace); reportErr != nil {
+			logger.Warningf("report pods of %s failed: %v", kind, reportErr)
+		}
+		return fmt.Errorf("scale %s: %w", kind, err)
+	}
+	logger.Infof("%s of nebula graph cluster %s is scaled to %d replicas", kind, name, replicas)
+	return nil
+}
+
+// reportStuckPods prints the pods of the component which are not ready and why
+func reportStuckPods(ctx context.Context, clientSet kubernetes.Interface, kind, name, namespace string) error {
+	podList, err := getComponentPods(ctx, clientSet, kind, name, namespace, false)
+	if err != nil {
+		return err
+	}
+	t := table.NewWriter()
+	t.SetOutputMirror(stdout)
+	t.AppendHeader(table.Row{"NAME", "STATUS", "NODE", "REASON"})
+	stuck := 0
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		issue := cluster.PodIssue(pod)
+		if issue == "" {
+			continue
+		}
+		stuck++
+		t.AppendRow(table.Row{pod.Name, pod.Status.Phase, pod.Spec.NodeName, issue})
+	}
+	if stuck == 0 {
+		return nil
+	}
+	logger.Warningf("%d pods of %s are not ready:", stuck, kind)
+	t.Render()
+	return nil
+}
@@ -28,43 +28,43 @@ const studioLabelKey = "ngctl/nebula-studio"
 
 func studioCmd() *cobra.Command {
 	var (
-		name      string
-		namespace string
-		nodePort  int32
-		image     string
+		name     string
+		nodePort int32
+		image    string
 	)
 	cmd := &cobra.Command{
 		Use:   "studio",
 		Short: "the command line tool for nebula graph studio",
 		Long:  "studio is a command line tool for nebula graph studio.",
-		Example: `  # installStudio nebula graph studio
-  ngctl studio installStudio --name studio --nodePort 30180
+		Example: `  # install nebula graph studio
+  ngctl studio install --name studio --nodePort 30180
+  # print the manifests of nebula graph studio without installing it
+  ngctl studio install --name studio --dry-run=client
   # uninstall nebula graph studio
   ngctl studio uninstall --name studio
 `,
 	}
 	cmd.PersistentFlags().StringVar(&name, "name", "studio", "name of the nebula graph studio")
-	cmd.PersistentFlags().StringVar(&namespace, "namespace", "default", "namespace of the nebula graph studio")
 
 	install := cobra.Command{
 		Use:   "install",
 		Short: "install nebula graph studio",
 		Long:  "install nebula graph studio.",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return installStudio(name, namespace, image, nodePort)
+			return installStudio(cmd.Context(), name, namespaceOrDefault(), image, nodePort)
 		},
 	}
 	install.PersistentFlags().Int32Var(&nodePort, "nodePort", 30180, "nodePort of the nebula graph studio")
 	install.PersistentFlags().StringVar(&image, "image", "vesoft/nebula-graph-studio:v3.7.0", "image of the nebula graph studio")
 
-	cmd.AddCommand(&install)
+	cmd.AddCommand(supportDryRun(&install))
 
 	uninstall := cobra.Command{
 		Use:   "uninstall",
 		Short: "uninstall nebula graph studio",
 		Long:  "uninstall nebula graph studio.",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return uninstallStudio(name, namespace)
+			return uninstallStudio(cmd.Context(), name, namespaceOrDefault())
 		},
 	}
 	cmd.AddCommand(&uninstall)
@@ -72,48 +72,48 @@ func studioCmd() *cobra.Command {
 	return cmd
 }
 
-func installStudio(name, namespace string, image string, nodePort int32) error {
-	clientSet, err := util.NewClientSet(kubeConfig)
-	if err != nil {
-		return err
-	}
+func installStudio(ctx context.Context, name, namespace string, image string, nodePort int32) error {
 	labels := map[string]string{
 		studioLabelKey: name,
 	}
-	var ctx = context.Background()
-
 	deploy := studio.CreateDeployment(name, namespace, labels, 1, image)
+	svc := studio.CreateService(name, namespace, labels, nodePort)
+	if dryRun == dryRunClient {
+		return printManifests(stdout, deploy, svc)
+	}
+
+	clientSet, err := newClientSet()
+	if err != nil {
+		return err
+	}
 	deployInterface := clientSet.AppsV1().Deployments(namespace)
-	err = util.CreateResource[appsv1.Deployment](ctx, deployInterface, deploy, name, studioLabelKey)
+	err = util.CreateOrUpdate[*appsv1.Deployment](ctx, deployInterface, deploy, studioLabelKey, dryRun == dryRunServer)
 	if err != nil {
 		return err
 	}
 
-	svc := studio.CreateService(name, namespace, labels, nodePort)
 	svcInterface := clientSet.CoreV1().Services(namespace)
-	err = util.CreateResource[corev1.Service](ctx, svcInterface, svc, name, studioLabelKey)
+	err = util.CreateOrUpdate[*corev1.Service](ctx, svcInterface, svc, studioLabelKey, dryRun == dryRunServer)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func uninstallStudio(name, namespace string) error {
-	clientSet, err := util.NewClientSet(kubeConfig)
+func uninstallStudio(ctx context.Context, name, namespace string) error {
+	clientSet, err := newClientSet()
 	if err != nil {
 		return err
 	}
-	var ctx = context.Background()
-
 	// remove deploy
 	deployments := clientSet.AppsV1().Deployments(namespace)
-	err = util.RemoveResource[appsv1.Deployment](ctx, deployments, name, studioLabelKey)
+	err = util.RemoveResource[*appsv1.Deployment](ctx, deployments, name, studioLabelKey)
 	if err != nil {
 		return err
 	}
 	// remove service
 	services := clientSet.CoreV1().Services(namespace)
-	err = util.RemoveResource[corev1.Service](ctx, services, name, studioLabelKey)
+	err = util.RemoveResource[*corev1.Service](ctx, services, name, studioLabelKey)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,133 @@
+/*
+ * Copyright (c) 2023 The nebula-contrib Authors.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *     http://www.apache.org/licenses/LICENSE-2.
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/yaml"
+
+	"github.com/nebula-contrib/ngctl/pkg/template"
+)
+
+// templateFlags maps the flags of the template command to the fields they override
+var templateFlags = map[string][]string{
+	"version":           {"spec.graphd.version", "spec.metad.version", "spec.storaged.version"},
+	"graphd-replicas":   {"spec.graphd.replicas"},
+	"metad-replicas":    {"spec.metad.replicas"},
+	"storaged-replicas": {"spec.storaged.replicas"},
+	"service-type":      {"spec.graphd.service.type"},
+	"storage-class": {
+		"spec.graphd.logVolumeClaim.storageClassName",
+		"spec.metad.logVolumeClaim.storageClassName",
+		"spec.metad.dataVolumeClaim.storageClassName",
+		"spec.storaged.logVolumeClaim.storageClassName",
+		"spec.storaged.dataVolumeClaims[*].storageClassName",
+	},
+}
+
+func templateCmd() *cobra.Command {
+	var (
+		profile string
+		name    string
+		list    bool
+		sets    []string
+	)
+	cmd := &cobra.Command{
+		Use:   "template",
+		Short: "generate a nebula graph cluster manifest",
+		Long: fmt.Sprintf(`generate a nebula graph cluster manifest from a built-in profile or a user defined template.
+user defined templates are NebulaCluster manifests at %s/<profile>.yaml.`, template.Dir()),
+		Example: `  # generate a manifest for production
+  ngctl template --profile prod --name nebula > cluster.yaml
+  # override any field of the profile
+  ngctl template --profile staging --storage-class fast --set spec.graphd.config.timezone_name=UTC+08:00
+  # list the profiles
+  ngctl template --list
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if list {
+				return listProfiles(stdout)
+			}
+			return generateTemplate(stdout, cmd.Flags(), profile, name, namespaceOrDefault(), sets)
+		},
+	}
+	flags := cmd.PersistentFlags()
+	flags.StringVar(&profile, "profile", "dev", "profile of the nebula graph cluster, such as dev|staging|prod")
+	flags.StringVar(&name, "name", "nebula", "name of the nebula graph cluster")
+	flags.BoolVar(&list, "list", false, "if set, list the available profiles")
+	flags.StringArrayVar(&sets, "set", nil, "override a field of the manifest, in the form of path=value")
+	flags.String("version", "", "version of the nebula graph cluster")
+	flags.Int32("graphd-replicas", 0, "replicas of graphd")
+	flags.Int32("metad-replicas", 0, "replicas of metad")
+	flags.Int32("storaged-replicas", 0, "replicas of storaged")
+	flags.String("storage-class", "", fmt.Sprintf("storage class of the volumes, the built-in profiles use %s if it is not set",
+		template.DefaultStorageClass))
+	flags.String("service-type", "", "service type of graphd, one of ClusterIP|NodePort|LoadBalancer")
+	return cmd
+}
+
+func generateTemplate(w io.Writer, flags *pflag.FlagSet, profile, name, namespace string, sets []string) error {
+	object, err := template.Load(profile, name, namespace)
+	if err != nil {
+		return err
+	}
+
+	// the flags are applied first so that --set has the final say
+	var overrides []string
+	flags.Visit(func(flag *pflag.Flag) {
+		for _, field := range templateFlags[flag.Name] {
+			overrides = append(overrides, fmt.Sprintf("%s=%s", field, flag.Value.String()))
+		}
+	})
+	for _, set := range append(overrides, sets...) {
+		path, value, err := template.ParseSet(set)
+		if err != nil {
+			return err
+		}
+		if err = template.Set(object, path, value); err != nil {
+			return err
+		}
+	}
+
+	content, err := yaml.Marshal(object)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(content)
+	return err
+}
+
+func listProfiles(w io.Writer) error {
+	profiles, err := template.Profiles()
+	if err != nil {
+		return err
+	}
+	t := table.NewWriter()
+	t.SetOutputMirror(w)
+	t.AppendHeader(table.Row{"PROFILE", "SOURCE"})
+	for _, profile := range profiles {
+		source := "built-in"
+		if !profile.BuiltIn {
+			source = profile.Path
+		}
+		t.AppendRow(table.Row{profile.Name, source})
+	}
+	t.Render()
+	return nil
+}
@@ -0,0 +1,149 @@
+/*
+ * Copyright (c) 2023 The nebula-contrib Authors.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *     http://www.apache.org/licenses/LICENSE-2.
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vesoft-inc/nebula-operator/apis/apps/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/nebula-contrib/ngctl/pkg/cluster"
+	"github.com/nebula-contrib/ngctl/pkg/logger"
+)
+
+func upgradeCmd() *cobra.Command {
+	var (
+		version   string
+		component string
+	)
+	cmd := &cobra.Command{
+		Use:   "upgrade",
+		Short: "upgrade the version of nebula graph cluster",
+		Long:  "upgrade the version of nebula graph cluster and follow the rolling update of each component.",
+		Example: `  # upgrade all components to v3.6.0
+  ngctl upgrade --version v3.6.0
+  # upgrade graphd only
+  ngctl upgrade --version v3.6.0 --component graphd
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if version == "" {
+				return errors.New("please specify the target version by --version")
+			}
+			return upgrade(cmd.Context(), version, component)
+		},
+	}
+	cmd.PersistentFlags().StringVar(&version, "version", "", "target version of the nebula graph cluster")
+	cmd.PersistentFlags().StringVar(&component, "component", "", "if set, only upgrade the component, one of graphd|metad|storaged")
+	return cmd
+}
+
+func upgrade(ctx context.Context, version, component string) error {
+	components := cluster.Components
+	switch component {
+	case "":
+	case graphd, metad, storaged:
+		components = []string{component}
+	default:
+		return errors.New("unsupported kind type of upgrade command")
+	}
+
+	name, namespace, err := selectCluster(ctx)
+	if err != nil {
+		return err
+	}
+
+	client, err := newDynamicClient()
+	if err != nil {
+		return err
+	}
+
+	nc, err := getCluster(ctx, client, name, namespace)
+	if err != nil {
+		return err
+	}
+
+	// preflight checks
+	var pending []string
+	for _, kind := range components {
+		current := cluster.ComponentVersion(nc, kind)
+		if current == version {
+			logger.Infof("%s is already %s", kind, version)
+			continue
+		}
+		if err = cluster.CheckUpgrade(current, version); err != nil {
+			return fmt.Errorf("%s: %w", kind, err)
+		}
+		pending = append(pending, kind)
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	if !cluster.Ready(nc) {
+		componentInfo(nc)
+		return errors.New("nebula graph cluster is not healthy, please check it before upgrade")
+	}
+
+	spec := map[string]interface{}{}
+	for _, kind := range pending {
+		spec[kind] = map[string]string{"version": version}
+	}
+	patch, err := json.Marshal(map[string]interface{}{"spec": spec})
+	if err != nil {
+		return err
+	}
+	resource := v1alpha1.GroupVersion.WithResource("nebulaclusters")
+	_, err = client.Resource(resource).Namespace(namespace).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return err
+	}
+	logger.Infof("upgrade nebula graph cluster %s to %s", name, version)
+
+	// the operator updates the components one by one in the order of metad, storaged and graphd
+	waitCtx, cancel := context.WithTimeout(ctx, waitTimeout(30*time.Minute))
+	defer cancel()
+	for _, kind := range pending {
+		var last string
+		err = cluster.Wait(waitCtx, client, name, namespace, func(nc *v1alpha1.NebulaCluster) bool {
+			status, _ := cluster.ComponentStatus(nc, kind)
+			return nc.Status.ObservedGeneration >= nc.Generation &&
+				status.Version == version && cluster.ComponentReady(nc, kind)
+		}, func(nc *v1alpha1.NebulaCluster) {
+			status, desired := cluster.ComponentStatus(nc, kind)
+			progress := fmt.Sprintf("%s phase: %s, ready: %d, desired: %d, updated: %d, version: %s",
+				kind, status.Phase, status.Workload.ReadyReplicas, desired, status.Workload.UpdatedReplicas, status.Version)
+			if progress != last {
+				logger.Infof("%s", progress)
+				last = progress
+			}
+		})
+		if err != nil {
+			return fmt.Errorf("upgrade %s: %w", kind, err)
+		}
+		logger.Infof("%s is upgraded to %s", kind, version)
+	}
+
+	nc, err = getCluster(ctx, client, name, namespace)
+	if err != nil {
+		return err
+	}
+	componentInfo(nc)
+	return nil
+}
@@ -16,54 +16,83 @@ package cmd
 import (
 	"context"
 	"errors"
-	"log"
 
 	"github.com/spf13/cobra"
 	"github.com/vesoft-inc/nebula-operator/apis/apps/v1alpha1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/nebula-contrib/ngctl/pkg/config"
-	"github.com/nebula-contrib/ngctl/pkg/util"
+	"github.com/nebula-contrib/ngctl/pkg/logger"
 )
 
 func useCmd() *cobra.Command {
 	var (
-		namespace string
+		contextName string
 	)
 	cmd := &cobra.Command{
 		Use:   "use",
 		Short: "Specify a Nebula Graph cluster to use",
+		Long: `specify a nebula graph cluster to use, it creates or updates a context named after the cluster
+or --context-name and switches to it, the other contexts are kept. if the context named after the cluster points to
+a cluster of the same name in another namespace or kubernetes cluster, the context is named
+<kube-context>/<namespace>/<name> instead.`,
+		Example: `  # use the nebula graph cluster nebula in namespace prod as the context prod
+  ngctl use nebula --namespace prod --context-name prod
+`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return useCluster(args, namespace)
+			return useCluster(cmd.Context(), args, namespaceOrDefault(), contextName)
 		},
 	}
-	cmd.PersistentFlags().StringVar(&namespace, "namespace", "default", "namespace of the nebula graph cluster")
+	cmd.PersistentFlags().StringVar(&contextName, "context-name", "", "name of the context, the name of the nebula graph cluster is used if empty and not taken by another cluster")
 	return cmd
 }
 
-func useCluster(args []string, namespace string) error {
+func useCluster(ctx context.Context, args []string, namespace, contextName string) error {
 	if len(args) == 0 {
 		return errors.New("please specify the name of Nebula Graph cluster")
 	}
 	name := args[0]
 
-	client, err := util.NewDynamicClient(kubeConfig)
+	client, err := newDynamicClient()
 	if err != nil {
 		return err
 	}
 
 	resource := v1alpha1.GroupVersion.WithResource("nebulaclusters")
 
-	ctx := context.Background()
 	_, err = client.Resource(resource).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
 		return err
 	}
 
-	err = config.SaveConfig(namespace, name)
+	return saveContext(contextName, name, namespace)
+}
+
+// saveContext creates or updates a context of the nebula graph cluster in the kubernetes context in use and uses it,
+// the context is named by config.NgctlConfig.DefaultName if contextName is empty
+func saveContext(contextName, name, namespace string) error {
+	conf := config.Context{
+		Name:       contextName,
+		Cluster:    name,
+		Namespace:  namespace,
+		KubeConfig: kubeConfig,
+	}
+	current, err := kubeOptions().CurrentContext()
 	if err != nil {
 		return err
 	}
-	log.Printf("use nebula graph cluster %s in namespace %s", name, namespace)
+	conf.KubeContext = current
+	if conf.Name == "" {
+		saved, err := config.Load()
+		if err != nil {
+			return err
+		}
+		conf.Name = saved.DefaultName(conf)
+		contextName = conf.Name
+	}
+	if err = config.UseContext(conf); err != nil {
+		return err
+	}
+	logger.Infof("use nebula graph cluster %s in namespace %s as context %s", name, namespace, contextName)
 	return nil
 }
@@ -0,0 +1,119 @@
+/*
+ * Copyright (c) 2023 The nebula-contrib Authors.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *     http://www.apache.org/licenses/LICENSE-2.
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+
+	"github.com/nebula-contrib/ngctl/pkg/manifest"
+	"github.com/nebula-contrib/ngctl/pkg/printer"
+	"github.com/nebula-contrib/ngctl/pkg/validate"
+)
+
+// validateResult is the json output of the validate command
+type validateResult struct {
+	Files    []string         `json:"files"`
+	Errors   int              `json:"errors"`
+	Warnings int              `json:"warnings"`
+	Issues   []validate.Issue `json:"issues"`
+}
+
+func validateCmd() *cobra.Command {
+	var (
+		file string
+	)
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "validate nebula graph cluster manifests offline",
+		Long: `validate nebula graph cluster manifests offline, no kubernetes cluster is needed.
+it reports unknown fields, missing required fields and risky settings, and exits with 1 if any error is found.`,
+		Example: `  # validate a manifest
+  ngctl validate -f cluster.yaml
+  # validate a generated manifest
+  ngctl template --profile prod | ngctl validate -f -
+  # validate all manifests in a directory and output json
+  ngctl validate -f manifests/ -o json
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if file == "" {
+				return errors.New("please specify the manifest file or directory by -f")
+			}
+			if output != printer.FormatTable && output != printer.FormatJSON && output != printer.FormatYAML {
+				return fmt.Errorf("unsupported output format %s of validate, one of json|yaml", output)
+			}
+			cmd.SilenceUsage = true
+			return validateManifests(stdout, file, output)
+		},
+	}
+	cmd.PersistentFlags().StringVarP(&file, "file", "f", "", "path of the manifest file or directory, - means stdin")
+	return cmd
+}
+
+func validateManifests(w io.Writer, path, output string) error {
+	// "-" validates the manifests read from stdin, such as the output of ngctl template
+	files := []string{path}
+	if path != "-" {
+		var err error
+		if files, err = validate.Files(path); err != nil {
+			return err
+		}
+	}
+	result := validateResult{Files: files, Issues: []validate.Issue{}}
+	for _, file := range files {
+		data, err := manifest.Read(file, stdin)
+		if err != nil {
+			return err
+		}
+		for _, issue := range validate.File(file, data) {
+			if issue.Severity == validate.SeverityError {
+				result.Errors++
+			} else {
+				result.Warnings++
+			}
+			result.Issues = append(result.Issues, issue)
+		}
+	}
+
+	switch output {
+	case printer.FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(result); err != nil {
+			return err
+		}
+	case printer.FormatYAML:
+		content, err := yaml.Marshal(result)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(content); err != nil {
+			return err
+		}
+	default:
+		for _, issue := range result.Issues {
+			_, _ = fmt.Fprintln(w, issue.String())
+		}
+		_, _ = fmt.Fprintf(w, "%d files validated, %d errors, %d warnings\n", len(files), result.Errors, result.Warnings)
+	}
+	if result.Errors > 0 {
+		return fmt.Errorf("%d errors found in %s", result.Errors, path)
+	}
+	return nil
+}
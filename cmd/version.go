@@ -15,12 +15,11 @@ package cmd
 
 import (
 	"context"
-	"log"
+	"fmt"
 
 	"github.com/spf13/cobra"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
-	"github.com/nebula-contrib/ngctl/pkg/util"
 	"github.com/nebula-contrib/ngctl/pkg/version"
 )
 
@@ -35,20 +34,19 @@ func versionCmd() *cobra.Command {
 		Short: "show the version of ngctl and nebula operator",
 		Long:  "show the version of ngctl and nebula operator.",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return ngctlVersion(namespace)
+			return ngctlVersion(cmd.Context(), namespace)
 		},
 	}
 	cmd.PersistentFlags().StringVar(&namespace, "operator-namespace", "nebula-operator-system", "namespace of nebula operator")
 	return cmd
 }
 
-func ngctlVersion(namespace string) error {
-	log.Printf("ngctl Version: %s", version.GetVersion())
-	set, err := util.NewClientSet(kubeConfig)
+func ngctlVersion(ctx context.Context, namespace string) error {
+	fmt.Fprintf(stdout, "ngctl Version: %s\n", version.GetVersion())
+	set, err := newClientSet()
 	if err != nil {
 		return err
 	}
-	ctx := context.Background()
 
 	controllers, err := set.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{
 		LabelSelector: OperatorSelector,
@@ -57,9 +55,9 @@ func ngctlVersion(namespace string) error {
 		return err
 	}
 	if len(controllers.Items) == 0 {
-		log.Printf("nebula operator is not installed")
+		fmt.Fprintln(stdout, "nebula operator is not installed")
 		return nil
 	}
-	log.Printf("Nebula Operator Version: %s", controllers.Items[0].Spec.Template.Spec.Containers[0].Image)
+	fmt.Fprintf(stdout, "Nebula Operator Version: %s\n", controllers.Items[0].Spec.Template.Spec.Containers[0].Image)
 	return nil
 }
@@ -14,15 +14,18 @@
 package main
 
 import (
-	"log"
+	"errors"
 	"os"
 
 	"github.com/nebula-contrib/ngctl/cmd"
+	"github.com/nebula-contrib/ngctl/pkg/logger"
 )
 
 func main() {
-	if err := cmd.RootCmd.Execute(); err != nil {
-		log.Println(err)
-		os.Exit(1)
+	if err := cmd.Execute(); err != nil {
+		if !errors.Is(err, cmd.ErrDiffer) {
+			logger.Errorf("%v", err)
+		}
+		os.Exit(cmd.ExitCode(err))
 	}
 }
@@ -0,0 +1,293 @@
+/*
+ * Copyright (c) 2023 The nebula-contrib Authors.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *     http://www.apache.org/licenses/LICENSE-2.
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package cluster
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/vesoft-inc/nebula-operator/apis/apps/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Option is the set of values used to build a nebula graph cluster
+type Option struct {
+	Name      string
+	Namespace string
+	Version   string
+
+	GraphdReplicas   int32
+	MetadReplicas    int32
+	StoragedReplicas int32
+
+	CPURequest    string
+	MemoryRequest string
+	CPULimit      string
+	MemoryLimit   string
+
+	StorageClass       string
+	MetadDataVolume    string
+	StoragedDataVolume string
+	LogVolume          string
+
+	ServiceType string
+
+	// AntiAffinity spreads the pods of each component across nodes, one of preferred|required
+	AntiAffinity string
+
+	LogRotate     int32
+	LogRotateSize string
+
+	// the exporter is not deployed if ExporterReplicas is zero
+	ExporterReplicas    int32
+	ExporterMaxRequests int32
+}
+
+const (
+	AntiAffinityPreferred = "preferred"
+	AntiAffinityRequired  = "required"
+)
+
+// Presets are the built-in options which can be selected by name
+var Presets = map[string]Option{
+	"dev": {
+		Version:            "v3.4.0",
+		GraphdReplicas:     1,
+		MetadReplicas:      1,
+		StoragedReplicas:   3,
+		CPURequest:         "100m",
+		MemoryRequest:      "100Mi",
+		CPULimit:           "1",
+		MemoryLimit:        "1Gi",
+		MetadDataVolume:    "5Gi",
+		StoragedDataVolume: "10Gi",
+		LogVolume:          "1Gi",
+		ServiceType:        string(corev1.ServiceTypeNodePort),
+		LogRotate:          5,
+		LogRotateSize:      "100M",
+	},
+	"staging": {
+		Version:             "v3.4.0",
+		GraphdReplicas:      2,
+		MetadReplicas:       3,
+		StoragedReplicas:    3,
+		CPURequest:          "500m",
+		MemoryRequest:       "1Gi",
+		CPULimit:            "2",
+		MemoryLimit:         "4Gi",
+		MetadDataVolume:     "10Gi",
+		StoragedDataVolume:  "50Gi",
+		LogVolume:           "5Gi",
+		ServiceType:         string(corev1.ServiceTypeNodePort),
+		AntiAffinity:        AntiAffinityPreferred,
+		LogRotate:           5,
+		LogRotateSize:       "200M",
+		ExporterReplicas:    1,
+		ExporterMaxRequests: 20,
+	},
+	"prod": {
+		Version:             "v3.4.0",
+		GraphdReplicas:      2,
+		MetadReplicas:       3,
+		StoragedReplicas:    3,
+		CPURequest:          "1",
+		MemoryRequest:       "2Gi",
+		CPULimit:            "4",
+		MemoryLimit:         "8Gi",
+		MetadDataVolume:     "20Gi",
+		StoragedDataVolume:  "100Gi",
+		LogVolume:           "10Gi",
+		ServiceType:         string(corev1.ServiceTypeClusterIP),
+		AntiAffinity:        AntiAffinityRequired,
+		LogRotate:           10,
+		LogRotateSize:       "500M",
+		ExporterReplicas:    1,
+		ExporterMaxRequests: 40,
+	},
+}
+
+// PresetNames returns the sorted names of the built-in presets
+func PresetNames() []string {
+	names := make([]string, 0, len(Presets))
+	for name := range Presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Build builds a nebula graph cluster from option
+func Build(option *Option) (*v1alpha1.NebulaCluster, error) {
+	resources, err := buildResources(option)
+	if err != nil {
+		return nil, err
+	}
+	logClaim, err := buildStorageClaim(option.LogVolume, option.StorageClass)
+	if err != nil {
+		return nil, err
+	}
+	metadClaim, err := buildStorageClaim(option.MetadDataVolume, option.StorageClass)
+	if err != nil {
+		return nil, err
+	}
+	storagedClaim, err := buildStorageClaim(option.StoragedDataVolume, option.StorageClass)
+	if err != nil {
+		return nil, err
+	}
+	pullPolicy := corev1.PullIfNotPresent
+
+	cluster := &v1alpha1.NebulaCluster{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1alpha1.GroupVersion.String(),
+			Kind:       "NebulaCluster",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      option.Name,
+			Namespace: option.Namespace,
+		},
+		Spec: v1alpha1.NebulaClusterSpec{
+			Graphd: &v1alpha1.GraphdSpec{
+				ComponentSpec: buildComponent(option, Graphd, option.GraphdReplicas, resources),
+				Service: &v1alpha1.GraphdServiceSpec{
+					ServiceSpec: v1alpha1.ServiceSpec{Type: corev1.ServiceType(option.ServiceType)},
+				},
+				LogVolumeClaim: logClaim,
+			},
+			Metad: &v1alpha1.MetadSpec{
+				ComponentSpec:   buildComponent(option, Metad, option.MetadReplicas, resources),
+				LogVolumeClaim:  logClaim.DeepCopy(),
+				DataVolumeClaim: metadClaim,
+			},
+			Storaged: &v1alpha1.StoragedSpec{
+				ComponentSpec:    buildComponent(option, Storaged, option.StoragedReplicas, resources),
+				LogVolumeClaim:   logClaim.DeepCopy(),
+				DataVolumeClaims: []v1alpha1.StorageClaim{*storagedClaim},
+			},
+			Reference: v1alpha1.WorkloadReference{
+				Name:    "statefulsets.apps",
+				Version: "v1",
+			},
+			SchedulerName:   corev1.DefaultSchedulerName,
+			ImagePullPolicy: &pullPolicy,
+		},
+	}
+	if option.LogRotate > 0 {
+		cluster.Spec.LogRotate = &v1alpha1.LogRotate{
+			Rotate: option.LogRotate,
+			Size:   option.LogRotateSize,
+		}
+	}
+	if option.ExporterReplicas > 0 {
+		replicas := option.ExporterReplicas
+		cluster.Spec.Exporter = &v1alpha1.ExporterSpec{
+			ComponentSpec: v1alpha1.ComponentSpec{
+				Replicas: &replicas,
+				Image:    "vesoft/nebula-stats-exporter",
+				Version:  "v3.3.0",
+			},
+			MaxRequests: option.ExporterMaxRequests,
+		}
+	}
+	return cluster, nil
+}
+
+func buildComponent(option *Option, component string, replicas int32, resources *corev1.ResourceRequirements) v1alpha1.ComponentSpec {
+	spec := v1alpha1.ComponentSpec{
+		Replicas:  &replicas,
+		Resources: resources.DeepCopy(),
+		Image:     "vesoft/nebula-" + component,
+		Version:   option.Version,
+	}
+	if option.AntiAffinity != "" {
+		spec.Affinity = buildAntiAffinity(option.Name, component, option.AntiAffinity == AntiAffinityRequired)
+	}
+	return spec
+}
+
+// buildAntiAffinity spreads the pods of a component across nodes
+func buildAntiAffinity(name, component string, required bool) *corev1.Affinity {
+	term := corev1.PodAffinityTerm{
+		LabelSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{
+				"app.kubernetes.io/cluster":   name,
+				"app.kubernetes.io/component": component,
+				"app.kubernetes.io/name":      "nebula-graph",
+			},
+		},
+		TopologyKey: corev1.LabelHostname,
+	}
+	antiAffinity := &corev1.PodAntiAffinity{}
+	if required {
+		antiAffinity.RequiredDuringSchedulingIgnoredDuringExecution = []corev1.PodAffinityTerm{term}
+	} else {
+		antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution = []corev1.WeightedPodAffinityTerm{{
+			Weight:          100,
+			PodAffinityTerm: term,
+		}}
+	}
+	return &corev1.Affinity{PodAntiAffinity: antiAffinity}
+}
+
+func buildResources(option *Option) (*corev1.ResourceRequirements, error) {
+	requests, err := buildResourceList(option.CPURequest, option.MemoryRequest)
+	if err != nil {
+		return nil, err
+	}
+	limits, err := buildResourceList(option.CPULimit, option.MemoryLimit)
+	if err != nil {
+		return nil, err
+	}
+	return &corev1.ResourceRequirements{
+		Requests: requests,
+		Limits:   limits,
+	}, nil
+}
+
+func buildResourceList(cpu, memory string) (corev1.ResourceList, error) {
+	list := corev1.ResourceList{}
+	if cpu != "" {
+		quantity, err := resource.ParseQuantity(cpu)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cpu %q: %w", cpu, err)
+		}
+		list[corev1.ResourceCPU] = quantity
+	}
+	if memory != "" {
+		quantity, err := resource.ParseQuantity(memory)
+		if err != nil {
+			return nil, fmt.Errorf("invalid memory %q: %w", memory, err)
+		}
+		list[corev1.ResourceMemory] = quantity
+	}
+	return list, nil
+}
+
+func buildStorageClaim(size, storageClass string) (*v1alpha1.StorageClaim, error) {
+	quantity, err := resource.ParseQuantity(size)
+	if err != nil {
+		return nil, fmt.Errorf("invalid volume size %q: %w", size, err)
+	}
+	claim := &v1alpha1.StorageClaim{
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceStorage: quantity},
+		},
+	}
+	// leave it empty to use the default storage class of kubernetes
+	if storageClass != "" {
+		claim.StorageClassName = &storageClass
+	}
+	return claim, nil
+}
@@ -0,0 +1,66 @@
+/*
+ * Copyright (c) 2023 The nebula-contrib Authors.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *     http://www.apache.org/licenses/LICENSE-2.
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package cluster
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// PodIssue returns the reason why the pod is not ready, or an empty string if it is ready
+func PodIssue(pod *corev1.Pod) string {
+	if pod.DeletionTimestamp != nil {
+		return "Terminating"
+	}
+	// scheduling failures such as insufficient resources or unbound PVCs
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionFalse {
+			return fmt.Sprintf("%s: %s", condition.Reason, condition.Message)
+		}
+	}
+	statuses := make([]corev1.ContainerStatus, 0, len(pod.Status.InitContainerStatuses)+len(pod.Status.ContainerStatuses))
+	statuses = append(statuses, pod.Status.InitContainerStatuses...)
+	statuses = append(statuses, pod.Status.ContainerStatuses...)
+	for i, status := range statuses {
+		if status.Ready {
+			continue
+		}
+		// an init container is never ready, it is done once it exits successfully
+		if i < len(pod.Status.InitContainerStatuses) && status.State.Terminated != nil && status.State.Terminated.ExitCode == 0 {
+			continue
+		}
+		if waiting := status.State.Waiting; waiting != nil && waiting.Reason != "" {
+			if waiting.Message != "" {
+				return fmt.Sprintf("%s: %s", waiting.Reason, waiting.Message)
+			}
+			return waiting.Reason
+		}
+		if terminated := status.State.Terminated; terminated != nil {
+			return fmt.Sprintf("%s: exit code %d", terminated.Reason, terminated.ExitCode)
+		}
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady && condition.Status != corev1.ConditionTrue {
+			if condition.Message != "" {
+				return condition.Message
+			}
+			return "not ready"
+		}
+	}
+	if pod.Status.Phase != corev1.PodRunning {
+		return string(pod.Status.Phase)
+	}
+	return ""
+}
@@ -0,0 +1,105 @@
+/*
+ * Copyright (c) 2023 The nebula-contrib Authors.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *     http://www.apache.org/licenses/LICENSE-2.
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package cluster
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/vesoft-inc/nebula-operator/apis/apps/v1alpha1"
+)
+
+// Version is a parsed nebula graph version such as v3.6.0
+type Version struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+// ParseVersion parses a version in the form of vX.Y.Z, the leading v and the patch are optional
+func ParseVersion(version string) (Version, error) {
+	var v Version
+	parts := strings.Split(strings.TrimPrefix(version, "v"), ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return v, fmt.Errorf("invalid version %q", version)
+	}
+	numbers := make([]int, 3)
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return v, fmt.Errorf("invalid version %q", version)
+		}
+		numbers[i] = n
+	}
+	v.Major, v.Minor, v.Patch = numbers[0], numbers[1], numbers[2]
+	return v, nil
+}
+
+// Compare returns -1, 0 or 1 if v is lower than, equal to or higher than other
+func (v Version) Compare(other Version) int {
+	for _, diff := range []int{v.Major - other.Major, v.Minor - other.Minor, v.Patch - other.Patch} {
+		if diff < 0 {
+			return -1
+		}
+		if diff > 0 {
+			return 1
+		}
+	}
+	return 0
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("v%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// CheckUpgrade checks whether it is allowed to upgrade from current to target,
+// downgrade and skipping a major version are not allowed
+func CheckUpgrade(current, target string) error {
+	from, err := ParseVersion(current)
+	if err != nil {
+		return err
+	}
+	to, err := ParseVersion(target)
+	if err != nil {
+		return err
+	}
+	if to.Compare(from) < 0 {
+		return fmt.Errorf("downgrade from %s to %s is not supported", current, target)
+	}
+	if to.Major-from.Major > 1 {
+		return fmt.Errorf("upgrade from %s to %s skips a major version", current, target)
+	}
+	return nil
+}
+
+// ComponentVersion returns the desired version of a component in the spec
+func ComponentVersion(cluster *v1alpha1.NebulaCluster, component string) string {
+	spec := cluster.Spec
+	switch component {
+	case Graphd:
+		if spec.Graphd != nil {
+			return spec.Graphd.Version
+		}
+	case Metad:
+		if spec.Metad != nil {
+			return spec.Metad.Version
+		}
+	case Storaged:
+		if spec.Storaged != nil {
+			return spec.Storaged.Version
+		}
+	}
+	return ""
+}
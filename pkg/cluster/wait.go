@@ -0,0 +1,118 @@
+/*
+ * Copyright (c) 2023 The nebula-contrib Authors.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *     http://www.apache.org/licenses/LICENSE-2.
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package cluster
+
+import (
+	"context"
+	"time"
+
+	"github.com/vesoft-inc/nebula-operator/apis/apps/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	Graphd   = "graphd"
+	Metad    = "metad"
+	Storaged = "storaged"
+)
+
+// Components are the components of a nebula graph cluster in the order they are started
+var Components = []string{Metad, Storaged, Graphd}
+
+const pollInterval = 2 * time.Second
+
+// Get gets a nebula graph cluster by name
+func Get(ctx context.Context, client dynamic.Interface, name, namespace string) (*v1alpha1.NebulaCluster, error) {
+	resource := v1alpha1.GroupVersion.WithResource("nebulaclusters")
+	r, err := client.Resource(resource).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	cluster := &v1alpha1.NebulaCluster{}
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(r.Object, cluster)
+	if err != nil {
+		return nil, err
+	}
+	return cluster, nil
+}
+
+// ComponentStatus returns the status and the desired replicas of a component
+func ComponentStatus(cluster *v1alpha1.NebulaCluster, component string) (v1alpha1.ComponentStatus, int32) {
+	var (
+		status   v1alpha1.ComponentStatus
+		replicas *int32
+	)
+	switch component {
+	case Graphd:
+		status = cluster.Status.Graphd
+		if cluster.Spec.Graphd != nil {
+			replicas = cluster.Spec.Graphd.Replicas
+		}
+	case Metad:
+		status = cluster.Status.Metad
+		if cluster.Spec.Metad != nil {
+			replicas = cluster.Spec.Metad.Replicas
+		}
+	case Storaged:
+		status = cluster.Status.Storaged.ComponentStatus
+		if cluster.Spec.Storaged != nil {
+			replicas = cluster.Spec.Storaged.Replicas
+		}
+	}
+	if replicas == nil {
+		return status, 0
+	}
+	return status, *replicas
+}
+
+// ComponentReady returns true if all replicas of the component are ready and running
+func ComponentReady(cluster *v1alpha1.NebulaCluster, component string) bool {
+	status, desired := ComponentStatus(cluster, component)
+	return status.Phase == v1alpha1.RunningPhase &&
+		status.Workload.ReadyReplicas == desired &&
+		status.Workload.Replicas == desired
+}
+
+// Ready returns true if the latest spec has been observed and all components are ready
+func Ready(cluster *v1alpha1.NebulaCluster) bool {
+	if cluster.Status.ObservedGeneration < cluster.Generation {
+		return false
+	}
+	for _, component := range Components {
+		if !ComponentReady(cluster, component) {
+			return false
+		}
+	}
+	return true
+}
+
+// Wait polls the nebula graph cluster until done returns true or ctx is done,
+// progress is called with every observed state of the cluster if it is not nil
+func Wait(ctx context.Context, client dynamic.Interface, name, namespace string,
+	done func(*v1alpha1.NebulaCluster) bool, progress func(*v1alpha1.NebulaCluster)) error {
+	return wait.PollUntilContextCancel(ctx, pollInterval, true, func(ctx context.Context) (bool, error) {
+		cluster, err := Get(ctx, client, name, namespace)
+		if err != nil {
+			return false, err
+		}
+		if progress != nil {
+			progress(cluster)
+		}
+		return done(cluster), nil
+	})
+}
@@ -14,6 +14,8 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path"
 	"path/filepath"
@@ -26,44 +28,188 @@ const (
 	NgctlConfigPath = ".ngctl/config"
 )
 
+// ErrNoContext is returned if no context is in use
+var ErrNoContext = errors.New("no nebula graph cluster is in use, please run ngctl use first")
+
 type NgctlConfig struct {
-	Namespace string `json:"namespace"`
-	Name      string `json:"name"`
+	CurrentContext string    `json:"currentContext"`
+	Contexts       []Context `json:"contexts"`
+
+	// Namespace and Name are the cluster in use of the config written by the older versions,
+	// they are converted to a context when the config is loaded
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name,omitempty"`
+}
+
+// Context is a named nebula graph cluster and the kubernetes cluster it belongs to
+type Context struct {
+	Name        string `json:"name"`
+	Cluster     string `json:"cluster"`
+	Namespace   string `json:"namespace"`
+	KubeConfig  string `json:"kubeconfig,omitempty"`
+	KubeContext string `json:"kubeContext,omitempty"`
+}
+
+func configPath() string {
+	return path.Join(homedir.HomeDir(), NgctlConfigPath)
+}
+
+// Load loads the config, an empty config is returned if the config file does not exist
+func Load() (*NgctlConfig, error) {
+	conf := &NgctlConfig{}
+	content, err := os.ReadFile(configPath())
+	if os.IsNotExist(err) {
+		return conf, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(content, conf); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", configPath(), err)
+	}
+	if conf.Name != "" && len(conf.Contexts) == 0 {
+		conf.Contexts = []Context{{Name: conf.Name, Cluster: conf.Name, Namespace: conf.Namespace}}
+		conf.CurrentContext = conf.Name
+	}
+	conf.Name, conf.Namespace = "", ""
+	return conf, nil
 }
 
-func SaveConfig(namespace string, name string) error {
-	configPath := path.Join(homedir.HomeDir(), NgctlConfigPath)
-	dir := filepath.Dir(configPath)
+// Save saves the config
+func (c *NgctlConfig) Save() error {
+	dir := filepath.Dir(configPath())
 	if _, err := os.Stat(dir); os.IsNotExist(err) {
 		// rwxr-x---
 		if err = os.MkdirAll(dir, 0750); err != nil {
 			return err
 		}
 	}
-	ncConfig := NgctlConfig{
-		Namespace: namespace,
-		Name:      name,
-	}
-	content, err := json.Marshal(ncConfig)
+	content, err := json.Marshal(c)
 	if err != nil {
 		return err
 	}
 	// rw-r-----
-	if err = os.WriteFile(configPath, content, 0640); err != nil {
-		return err
+	return os.WriteFile(configPath(), content, 0640)
+}
+
+// Context returns the context by name
+func (c *NgctlConfig) Context(name string) (*Context, bool) {
+	for i := range c.Contexts {
+		if c.Contexts[i].Name == name {
+			return &c.Contexts[i], true
+		}
+	}
+	return nil, false
+}
+
+// Current returns the context in use
+func (c *NgctlConfig) Current() (*Context, error) {
+	if c.CurrentContext == "" {
+		return nil, ErrNoContext
+	}
+	context, ok := c.Context(c.CurrentContext)
+	if !ok {
+		return nil, fmt.Errorf("current context %s is not found", c.CurrentContext)
+	}
+	return context, nil
+}
+
+// Set creates or updates a context by its name
+func (c *NgctlConfig) Set(context Context) {
+	if current, ok := c.Context(context.Name); ok {
+		*current = context
+		return
+	}
+	c.Contexts = append(c.Contexts, context)
+}
+
+// DefaultName returns the name of a new context of the nebula graph cluster, it is the name of the cluster unless a
+// context of that name points to another cluster, then it is <kube-context>/<namespace>/<cluster> so that the
+// contexts of the clusters of the same name in different namespaces or kubernetes clusters do not overwrite each other
+func (c *NgctlConfig) DefaultName(context Context) string {
+	existing, ok := c.Context(context.Cluster)
+	if !ok || existing.samePlace(context) {
+		return context.Cluster
+	}
+	return path.Join(context.KubeContext, context.Namespace, context.Cluster)
+}
+
+// samePlace returns true if the contexts point to the same nebula graph cluster
+func (c *Context) samePlace(other Context) bool {
+	return c.Cluster == other.Cluster && c.Namespace == other.Namespace &&
+		c.KubeConfig == other.KubeConfig && c.KubeContext == other.KubeContext
+}
+
+// Rename renames a context
+func (c *NgctlConfig) Rename(from, to string) error {
+	context, ok := c.Context(from)
+	if !ok {
+		return fmt.Errorf("context %s is not found", from)
+	}
+	if _, ok = c.Context(to); ok {
+		return fmt.Errorf("context %s already exists", to)
+	}
+	context.Name = to
+	if c.CurrentContext == from {
+		c.CurrentContext = to
 	}
 	return nil
 }
 
-func LoadConfig() (NgctlConfig, error) {
-	var conf NgctlConfig
-	configPath := path.Join(homedir.HomeDir(), NgctlConfigPath)
-	content, err := os.ReadFile(configPath)
+// Delete deletes a context, the current context is unset if it is deleted
+func (c *NgctlConfig) Delete(name string) error {
+	for i := range c.Contexts {
+		if c.Contexts[i].Name == name {
+			c.Contexts = append(c.Contexts[:i], c.Contexts[i+1:]...)
+			if c.CurrentContext == name {
+				c.CurrentContext = ""
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("context %s is not found", name)
+}
+
+// UseContext creates or updates a context and uses it
+func UseContext(context Context) error {
+	conf, err := Load()
 	if err != nil {
-		return conf, err
+		return err
 	}
-	if err = json.Unmarshal(content, &conf); err != nil {
-		return conf, err
+	conf.Set(context)
+	conf.CurrentContext = context.Name
+	return conf.Save()
+}
+
+// LoadConfig returns the context in use
+func LoadConfig() (*Context, error) {
+	conf, err := Load()
+	if err != nil {
+		return nil, err
 	}
-	return conf, nil
+	return conf.Current()
+}
+
+// RemoveCluster deletes the contexts pointing to the same nebula graph cluster as cluster and returns their names,
+// the contexts of the clusters of the same name in other kubernetes contexts or kubeconfigs are kept
+func RemoveCluster(cluster Context) ([]string, error) {
+	conf, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	var removed []string
+	for _, context := range conf.Contexts {
+		if context.samePlace(cluster) {
+			removed = append(removed, context.Name)
+		}
+	}
+	if len(removed) == 0 {
+		return nil, nil
+	}
+	for _, context := range removed {
+		if err = conf.Delete(context); err != nil {
+			return nil, err
+		}
+	}
+	return removed, conf.Save()
 }
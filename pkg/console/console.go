@@ -26,10 +26,10 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/kubernetes/scheme"
 	v1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/rest"
-	"k8s.io/client-go/tools/remotecommand"
+
+	"github.com/nebula-contrib/ngctl/pkg/exec"
 )
 
 const graphdServiceSelector = "app.kubernetes.io/cluster=%s,app.kubernetes.io/component=graphd,app.kubernetes.io/name=nebula-graph"
@@ -58,37 +58,21 @@ const (
 	mountPathPrefix  = "/etc/nebula"
 )
 
-func RunShell(ctx context.Context, clientSet *kubernetes.Clientset, config *rest.Config, option *Option) error {
+// RunShell runs nebula console in the console pod, the terminal is restored when the shell exits or ctx is done
+func RunShell(ctx context.Context, clientSet kubernetes.Interface, config *rest.Config, option *Option) error {
 
-	svcName, err := getService(context.Background(), clientSet.CoreV1(), option)
+	svcName, err := getService(ctx, clientSet.CoreV1(), option)
 	if err != nil {
 		return err
 	}
 	option.GraphdServiceName = svcName
 
-	req := PodExecReq(clientSet, option)
-
-	exec, err := remotecommand.NewSPDYExecutor(config, "POST", req.URL())
-	if err != nil {
-		return err
-	}
-	in := streams.NewIn(os.Stdin)
-	if err = in.SetRawTerminal(); err != nil {
-		return err
-	}
-	defer in.RestoreTerminal()
-	return exec.StreamWithContext(ctx,
-		remotecommand.StreamOptions{
-			Stdin:  in,
-			Stdout: os.Stdout,
-			Stderr: os.Stderr,
-		})
+	return exec.Run(ctx, clientSet, config, PodExecOptions(option))
 }
 
-func PodExecReq(clientSet *kubernetes.Clientset, option *Option) *rest.Request {
-	podName, namespace := option.PodName, option.Namespace
-
-	address := fmt.Sprintf("%s.%s.svc.cluster.local", option.GraphdServiceName, namespace)
+// PodExecOptions returns the options of running nebula console in the console pod with a terminal
+func PodExecOptions(option *Option) *exec.Options {
+	address := fmt.Sprintf("%s.%s.svc.cluster.local", option.GraphdServiceName, option.Namespace)
 
 	cmd := []string{"/usr/local/bin/nebula-console", "-addr", address, "-port", "9669", "-u", option.Username, "-p", option.Password}
 	if option.Timeout > 0 {
@@ -113,20 +97,16 @@ func PodExecReq(clientSet *kubernetes.Clientset, option *Option) *rest.Request {
 		cmd = append(cmd, "-ssl_private_key_path", path.Join(mountPathPrefix, sslPrivateKeyKey))
 	}
 
-	req := clientSet.CoreV1().RESTClient().
-		Post().Resource("pods").
-		Name(podName).Namespace(namespace).
-		SubResource("exec")
-
-	req.VersionedParams(&corev1.PodExecOptions{
-		Container: podName,
+	return &exec.Options{
+		Namespace: option.Namespace,
+		Pod:       option.PodName,
+		Container: option.PodName,
 		Command:   cmd,
-		Stdin:     true,
-		Stdout:    true,
-		Stderr:    true,
+		Stdin:     streams.NewIn(os.Stdin),
+		Stdout:    streams.NewOut(os.Stdout),
+		Stderr:    os.Stderr,
 		TTY:       true,
-	}, scheme.ParameterCodec)
-	return req
+	}
 }
 
 func CratePod(name, namespace string, labels map[string]string, image string, option *Option) *corev1.Pod {
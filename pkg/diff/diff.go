@@ -0,0 +1,253 @@
+/*
+ * Copyright (c) 2023 The nebula-contrib Authors.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *     http://www.apache.org/licenses/LICENSE-2.
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+type ChangeType string
+
+const (
+	Added    ChangeType = "+"
+	Removed  ChangeType = "-"
+	Modified ChangeType = "~"
+)
+
+// Change is a difference of a single field
+type Change struct {
+	Type ChangeType  `json:"type"`
+	Path string      `json:"path"`
+	Old  interface{} `json:"old,omitempty"`
+	New  interface{} `json:"new,omitempty"`
+}
+
+// Normalize returns a copy of the object without status and the metadata maintained by the server
+func Normalize(object *unstructured.Unstructured) map[string]interface{} {
+	if object == nil {
+		return map[string]interface{}{}
+	}
+	content := object.DeepCopy().Object
+	delete(content, "status")
+
+	metadata := map[string]interface{}{}
+	if old, ok := content["metadata"].(map[string]interface{}); ok {
+		for _, key := range []string{"name", "namespace", "labels", "annotations"} {
+			if value, ok := old[key]; ok {
+				metadata[key] = value
+			}
+		}
+	}
+	if annotations, ok := metadata["annotations"].(map[string]interface{}); ok {
+		delete(annotations, lastAppliedAnnotation)
+		if len(annotations) == 0 {
+			delete(metadata, "annotations")
+		}
+	}
+	content["metadata"] = metadata
+	return content
+}
+
+// Flatten flattens a nested object into a map from field path to leaf value
+func Flatten(object map[string]interface{}) map[string]interface{} {
+	fields := map[string]interface{}{}
+	flatten("", object, fields)
+	return fields
+}
+
+func flatten(prefix string, value interface{}, fields map[string]interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 && prefix != "" {
+			fields[prefix] = v
+			return
+		}
+		for key, elem := range v {
+			path := key
+			if prefix != "" {
+				path = prefix + "." + key
+			}
+			flatten(path, elem, fields)
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			fields[prefix] = v
+			return
+		}
+		for i, elem := range v {
+			flatten(fmt.Sprintf("%s[%d]", prefix, i), elem, fields)
+		}
+	default:
+		fields[prefix] = v
+	}
+}
+
+// Prune removes the empty fields and the fields equal to their defaults from object,
+// the keys of defaults are field paths where * matches any single segment
+func Prune(object map[string]interface{}, defaults map[string]interface{}) {
+	prune("", object, defaults)
+}
+
+// prune prunes value in place and returns true if value itself should be removed
+func prune(path string, value interface{}, defaults map[string]interface{}) bool {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, elem := range v {
+			child := key
+			if path != "" {
+				child = path + "." + key
+			}
+			if prune(child, elem, defaults) {
+				delete(v, key)
+			}
+		}
+		return len(v) == 0
+	case []interface{}:
+		// elements are kept to preserve the indexes
+		for i, elem := range v {
+			prune(fmt.Sprintf("%s[%d]", path, i), elem, defaults)
+		}
+		return len(v) == 0
+	}
+	if isEmpty(value) {
+		return true
+	}
+	for pattern, defaultValue := range defaults {
+		if matchPath(pattern, path) && equal(value, defaultValue) {
+			return true
+		}
+	}
+	return false
+}
+
+// PruneNil removes the nil values and the empty maps and slices from object, unlike Prune the zero values such as ""
+// and false are kept since they may be set explicitly
+func PruneNil(object map[string]interface{}) {
+	pruneNil(object)
+}
+
+// pruneNil prunes value in place and returns true if value itself should be removed
+func pruneNil(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case map[string]interface{}:
+		for key, elem := range v {
+			if pruneNil(elem) {
+				delete(v, key)
+			}
+		}
+		return len(v) == 0
+	case []interface{}:
+		// elements are kept to preserve the indexes
+		for _, elem := range v {
+			pruneNil(elem)
+		}
+		return len(v) == 0
+	}
+	return false
+}
+
+func isEmpty(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case bool:
+		return !v
+	}
+	return false
+}
+
+func matchPath(pattern, path string) bool {
+	patterns, paths := strings.Split(pattern, "."), strings.Split(path, ".")
+	if len(patterns) != len(paths) {
+		return false
+	}
+	for i := range patterns {
+		if patterns[i] != "*" && patterns[i] != paths[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Compare returns the field level changes from old to new sorted by path
+func Compare(old, new map[string]interface{}) []Change {
+	oldFields, newFields := Flatten(old), Flatten(new)
+	var changes []Change
+	for path, oldValue := range oldFields {
+		newValue, ok := newFields[path]
+		if !ok {
+			changes = append(changes, Change{Type: Removed, Path: path, Old: oldValue})
+		} else if !equal(oldValue, newValue) {
+			changes = append(changes, Change{Type: Modified, Path: path, Old: oldValue, New: newValue})
+		}
+	}
+	for path, newValue := range newFields {
+		if _, ok := oldFields[path]; !ok {
+			changes = append(changes, Change{Type: Added, Path: path, New: newValue})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes
+}
+
+// equal compares leaf values, numbers are compared by their json representation
+// since the same value may be decoded as int64 or float64
+func equal(a, b interface{}) bool {
+	if reflect.DeepEqual(a, b) {
+		return true
+	}
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(ja) == string(jb)
+}
+
+// Print prints the changes line by line
+func Print(w io.Writer, changes []Change) {
+	for _, change := range changes {
+		switch change.Type {
+		case Added:
+			_, _ = fmt.Fprintf(w, "%s %s: %s\n", change.Type, change.Path, format(change.New))
+		case Removed:
+			_, _ = fmt.Fprintf(w, "%s %s: %s\n", change.Type, change.Path, format(change.Old))
+		case Modified:
+			_, _ = fmt.Fprintf(w, "%s %s: %s -> %s\n", change.Type, change.Path, format(change.Old), format(change.New))
+		}
+	}
+}
+
+func format(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	content, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(content)
+}
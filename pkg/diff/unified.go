@@ -0,0 +1,162 @@
+/*
+ * Copyright (c) 2023 The nebula-contrib Authors.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *     http://www.apache.org/licenses/LICENSE-2.
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package diff
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+const contextLines = 3
+
+type operation struct {
+	kind byte // ' ', '-' or '+'
+	line string
+}
+
+// Unified writes the unified diff from oldText to newText, nothing is written if they are equal
+func Unified(w io.Writer, oldName, newName, oldText, newText string) {
+	ops := lineDiff(splitLines(oldText), splitLines(newText))
+	hunks := groupHunks(ops)
+	if len(hunks) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(w, "--- %s\n+++ %s\n", oldName, newName)
+	for _, h := range hunks {
+		_, _ = fmt.Fprintf(w, "@@ -%s +%s @@\n", hunkRange(h.oldStart, h.oldLines), hunkRange(h.newStart, h.newLines))
+		for _, op := range h.ops {
+			_, _ = fmt.Fprintf(w, "%c%s\n", op.kind, op.line)
+		}
+	}
+}
+
+func splitLines(text string) []string {
+	text = strings.TrimSuffix(text, "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+// lineDiff computes the edit script from a to b by the longest common subsequence
+func lineDiff(a, b []string) []operation {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	var ops []operation
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, operation{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, operation{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, operation{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, operation{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, operation{'+', b[j]})
+	}
+	return ops
+}
+
+type hunk struct {
+	oldStart, oldLines int
+	newStart, newLines int
+	ops                []operation
+}
+
+// groupHunks groups the changed lines with their surrounding context into hunks
+func groupHunks(ops []operation) []hunk {
+	var hunks []hunk
+	oldLine, newLine := 1, 1
+	for start := 0; start < len(ops); {
+		if ops[start].kind == ' ' {
+			oldLine++
+			newLine++
+			start++
+			continue
+		}
+		// include the leading context
+		from := start - contextLines
+		if from < 0 {
+			from = 0
+		}
+		h := hunk{oldStart: oldLine - (start - from), newStart: newLine - (start - from)}
+		// extend the hunk until there are more than 2*contextLines unchanged lines
+		end, unchanged := start, 0
+		for end < len(ops) && unchanged <= 2*contextLines {
+			if ops[end].kind == ' ' {
+				unchanged++
+			} else {
+				unchanged = 0
+			}
+			end++
+		}
+		if unchanged > contextLines {
+			end -= unchanged - contextLines
+		}
+		h.ops = ops[from:end]
+		for _, op := range h.ops {
+			if op.kind != '+' {
+				h.oldLines++
+			}
+			if op.kind != '-' {
+				h.newLines++
+			}
+		}
+		for _, op := range ops[start:end] {
+			if op.kind != '+' {
+				oldLine++
+			}
+			if op.kind != '-' {
+				newLine++
+			}
+		}
+		hunks = append(hunks, h)
+		start = end
+	}
+	return hunks
+}
+
+func hunkRange(start, lines int) string {
+	if lines == 0 {
+		start--
+	}
+	if lines == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, lines)
+}
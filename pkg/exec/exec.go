@@ -0,0 +1,157 @@
+/*
+ * Copyright (c) 2023 The nebula-contrib Authors.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *     http://www.apache.org/licenses/LICENSE-2.
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package exec
+
+import (
+	"context"
+	"io"
+	"os"
+	"time"
+
+	"github.com/docker/cli/cli/streams"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+
+	"github.com/nebula-contrib/ngctl/pkg/logger"
+)
+
+// resizeInterval is the interval of checking the size of the local terminal, it is polled since SIGWINCH is not
+// available on windows
+const resizeInterval = 250 * time.Millisecond
+
+// Options are the options of a command executed in a container of a pod
+type Options struct {
+	Namespace string
+	Pod       string
+	Container string
+	Command   []string
+	// Stdin is passed to the command if it is not nil
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+	// TTY allocates a terminal for the command, the stderr is merged into the stdout by the terminal
+	TTY bool
+}
+
+// NewStdin wraps r as the stdin of a command, a file such as os.Stdin is passed unwrapped so that a terminal is
+// detected by its file descriptor
+func NewStdin(r io.Reader) *streams.In {
+	if file, ok := r.(*os.File); ok {
+		return streams.NewIn(file)
+	}
+	return streams.NewIn(io.NopCloser(r))
+}
+
+// Request builds the exec request of the command
+func Request(clientSet kubernetes.Interface, o *Options) *rest.Request {
+	req := clientSet.CoreV1().RESTClient().
+		Post().Resource("pods").
+		Name(o.Pod).Namespace(o.Namespace).
+		SubResource("exec")
+
+	req.VersionedParams(&corev1.PodExecOptions{
+		Container: o.Container,
+		Command:   o.Command,
+		Stdin:     o.Stdin != nil,
+		Stdout:    o.Stdout != nil,
+		Stderr:    o.Stderr != nil && !o.TTY,
+		TTY:       o.TTY,
+	}, scheme.ParameterCodec)
+	return req
+}
+
+// Run runs the command until it exits or ctx is done. with TTY, the local terminal is set to raw mode and restored
+// when the command exits, and the size of the remote terminal follows the local one. a non-zero exit code of the
+// command is returned as k8s.io/client-go/util/exec.CodeExitError
+func Run(ctx context.Context, clientSet kubernetes.Interface, config *rest.Config, o *Options) error {
+	executor, err := remotecommand.NewSPDYExecutor(config, "POST", Request(clientSet, o).URL())
+	if err != nil {
+		return err
+	}
+	options := remotecommand.StreamOptions{
+		Stdin:  o.Stdin,
+		Stdout: o.Stdout,
+		Stderr: o.Stderr,
+		Tty:    o.TTY,
+	}
+	if o.TTY {
+		options.Stderr = nil
+		if in, ok := o.Stdin.(*streams.In); ok && in.IsTerminal() {
+			if err = in.SetRawTerminal(); err != nil {
+				return err
+			}
+			defer in.RestoreTerminal()
+		}
+		if out, ok := o.Stdout.(*streams.Out); ok && out.IsTerminal() {
+			queue := newSizeQueue(out)
+			defer queue.stop()
+			options.TerminalSizeQueue = queue
+		}
+	}
+	return executor.StreamWithContext(ctx, options)
+}
+
+// sizeQueue sends the size of the local terminal when it changes
+type sizeQueue struct {
+	sizes chan remotecommand.TerminalSize
+	done  chan struct{}
+}
+
+func newSizeQueue(out *streams.Out) *sizeQueue {
+	q := &sizeQueue{
+		sizes: make(chan remotecommand.TerminalSize, 1),
+		done:  make(chan struct{}),
+	}
+	go func() {
+		defer close(q.sizes)
+		var last remotecommand.TerminalSize
+		ticker := time.NewTicker(resizeInterval)
+		defer ticker.Stop()
+		for {
+			height, width := out.GetTtySize()
+			size := remotecommand.TerminalSize{Width: uint16(width), Height: uint16(height)}
+			if size != last && size.Width > 0 && size.Height > 0 {
+				logger.V(4).Infof("resize the terminal to %dx%d", size.Width, size.Height)
+				select {
+				case q.sizes <- size:
+					last = size
+				case <-q.done:
+					return
+				}
+			}
+			select {
+			case <-ticker.C:
+			case <-q.done:
+				return
+			}
+		}
+	}()
+	return q
+}
+
+// Next returns the next size of the terminal, nil is returned after the queue is stopped
+func (q *sizeQueue) Next() *remotecommand.TerminalSize {
+	size, ok := <-q.sizes
+	if !ok {
+		return nil
+	}
+	return &size
+}
+
+func (q *sizeQueue) stop() {
+	close(q.done)
+}
@@ -0,0 +1,150 @@
+/*
+ * Copyright (c) 2023 The nebula-contrib Authors.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *     http://www.apache.org/licenses/LICENSE-2.
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package factory
+
+import (
+	"errors"
+	"sync"
+
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/nebula-contrib/ngctl/pkg/util"
+)
+
+// Factory hands out the kubernetes clients of a command
+type Factory interface {
+	// RESTConfig returns the rest config shared by all the clients
+	RESTConfig() (*rest.Config, error)
+	ClientSet() (kubernetes.Interface, error)
+	DynamicClient() (dynamic.Interface, error)
+	DiscoveryClient() (discovery.DiscoveryInterface, error)
+}
+
+// factory loads the rest config once and creates each client once
+type factory struct {
+	options *util.KubeOptions
+
+	mu        sync.Mutex
+	config    *rest.Config
+	clientSet kubernetes.Interface
+	dynamic   dynamic.Interface
+}
+
+// New returns a factory which loads the rest config by options
+func New(options *util.KubeOptions) Factory {
+	return &factory{options: options}
+}
+
+func (f *factory) RESTConfig() (*rest.Config, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.restConfig()
+}
+
+func (f *factory) restConfig() (*rest.Config, error) {
+	if f.config != nil {
+		return f.config, nil
+	}
+	config, err := f.options.RESTConfig()
+	if err != nil {
+		return nil, err
+	}
+	f.config = config
+	return config, nil
+}
+
+func (f *factory) ClientSet() (kubernetes.Interface, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.clientSet != nil {
+		return f.clientSet, nil
+	}
+	config, err := f.restConfig()
+	if err != nil {
+		return nil, err
+	}
+	clientSet, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	f.clientSet = clientSet
+	return clientSet, nil
+}
+
+func (f *factory) DynamicClient() (dynamic.Interface, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.dynamic != nil {
+		return f.dynamic, nil
+	}
+	config, err := f.restConfig()
+	if err != nil {
+		return nil, err
+	}
+	client, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	f.dynamic = client
+	return client, nil
+}
+
+func (f *factory) DiscoveryClient() (discovery.DiscoveryInterface, error) {
+	clientSet, err := f.ClientSet()
+	if err != nil {
+		return nil, err
+	}
+	return clientSet.Discovery(), nil
+}
+
+// Fake is a factory which hands out the injected clients, it is used in tests
+type Fake struct {
+	Config  *rest.Config
+	Typed   kubernetes.Interface
+	Dynamic dynamic.Interface
+}
+
+var errNotInjected = errors.New("the client is not injected into the fake factory")
+
+func (f *Fake) RESTConfig() (*rest.Config, error) {
+	if f.Config == nil {
+		return &rest.Config{Host: "https://fake"}, nil
+	}
+	return f.Config, nil
+}
+
+func (f *Fake) ClientSet() (kubernetes.Interface, error) {
+	if f.Typed == nil {
+		return nil, errNotInjected
+	}
+	return f.Typed, nil
+}
+
+func (f *Fake) DynamicClient() (dynamic.Interface, error) {
+	if f.Dynamic == nil {
+		return nil, errNotInjected
+	}
+	return f.Dynamic, nil
+}
+
+func (f *Fake) DiscoveryClient() (discovery.DiscoveryInterface, error) {
+	clientSet, err := f.ClientSet()
+	if err != nil {
+		return nil, err
+	}
+	return clientSet.Discovery(), nil
+}
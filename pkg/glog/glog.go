@@ -0,0 +1,119 @@
+/*
+ * Copyright (c) 2023 The nebula-contrib Authors.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *     http://www.apache.org/licenses/LICENSE-2.
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package glog
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Severity is the severity of a glog record
+type Severity int
+
+const (
+	Info Severity = iota
+	Warning
+	Error
+	Fatal
+)
+
+var severityNames = []string{"INFO", "WARNING", "ERROR", "FATAL"}
+
+func (s Severity) String() string {
+	if s < Info || s > Fatal {
+		return "UNKNOWN"
+	}
+	return severityNames[s]
+}
+
+// ParseSeverity parses a severity by its letter or its name case-insensitively, such as E, error or ERROR
+func ParseSeverity(s string) (Severity, error) {
+	upper := strings.ToUpper(strings.TrimSpace(s))
+	for i, name := range severityNames {
+		if upper == name || upper == name[:1] {
+			return Severity(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown severity %s, one of I|W|E|F", s)
+}
+
+// Record is a line of the glog format `Lyyyymmdd hh:mm:ss.uuuuuu thread file:line] msg` logged by the nebula
+// graph components, the year is optional as in the original glog format
+type Record struct {
+	Severity Severity
+	Time     time.Time
+	Thread   int
+	File     string
+	Line     int
+	Message  string
+}
+
+const timeLayout = "20060102 15:04:05.000000"
+
+var header = regexp.MustCompile(`^([IWEF])(\d{8}|\d{4}) (\d{2}:\d{2}:\d{2}\.\d{6})\s+(\d+) ([^:\]]+):(\d+)\] ?(.*)$`)
+
+// Parse parses a glog line, false is returned if the line is not in the glog format, such as a line of a stack trace.
+// the timestamp is parsed in UTC since the time zone of the pod is unknown, the year of a line without one is
+// inferred from the current time as in ParseAt
+func Parse(line string) (Record, bool) {
+	return ParseAt(line, time.Now())
+}
+
+// ParseAt parses a glog line like Parse, the year of a line without one is the year of now, or the previous year if
+// the timestamp is more than a day after now, such as a line of December read in January, or the date is only valid
+// in the previous year, such as the 29th of February of a leap year read in the next year
+func ParseAt(line string, now time.Time) (Record, bool) {
+	match := header.FindStringSubmatch(strings.TrimRight(line, "\r\n"))
+	if match == nil {
+		return Record{}, false
+	}
+	severity, _ := ParseSeverity(match[1])
+	timestamp, err := parseTime(match[2], match[3], now)
+	if err != nil {
+		return Record{}, false
+	}
+	thread, _ := strconv.Atoi(match[4])
+	lineNumber, _ := strconv.Atoi(match[6])
+	return Record{
+		Severity: severity,
+		Time:     timestamp,
+		Thread:   thread,
+		File:     match[5],
+		Line:     lineNumber,
+		Message:  match[7],
+	}, true
+}
+
+// parseTime parses the date and the time of a glog line. a date without year is tried in the year of now first, and
+// in the previous year if it is not a valid date in the year of now, such as 0229, or it is more than a day after
+// now. a day of tolerance is left for the clock skew between the pod and the local machine
+func parseTime(date, clock string, now time.Time) (time.Time, error) {
+	if len(date) != 4 {
+		return time.Parse(timeLayout, date+" "+clock)
+	}
+	now = now.UTC()
+	current, err := time.Parse(timeLayout, strconv.Itoa(now.Year())+date+" "+clock)
+	if err == nil && !current.After(now.Add(24*time.Hour)) {
+		return current, nil
+	}
+	previous, prevErr := time.Parse(timeLayout, strconv.Itoa(now.Year()-1)+date+" "+clock)
+	if prevErr != nil && err == nil {
+		// the date is only valid in the year of now, such as 0229 of a leap year
+		return current, nil
+	}
+	return previous, prevErr
+}
@@ -15,6 +15,7 @@ package list
 
 import (
 	"context"
+	"sort"
 
 	"github.com/vesoft-inc/nebula-operator/apis/apps/v1alpha1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -23,7 +24,8 @@ import (
 	"k8s.io/client-go/dynamic"
 )
 
-func Clusters(ctx context.Context, client *dynamic.DynamicClient, allNamespaces bool, namespace string) ([]v1alpha1.NebulaCluster, error) {
+// Clusters lists the nebula graph clusters ordered by namespace and name
+func Clusters(ctx context.Context, client dynamic.Interface, allNamespaces bool, namespace string) ([]v1alpha1.NebulaCluster, error) {
 	resource := v1alpha1.GroupVersion.WithResource("nebulaclusters")
 	resourceInterface := client.Resource(resource)
 	var clusterList *unstructured.UnstructuredList
@@ -46,5 +48,11 @@ func Clusters(ctx context.Context, client *dynamic.DynamicClient, allNamespaces
 		}
 		clusters = append(clusters, cluster)
 	}
+	sort.Slice(clusters, func(i, j int) bool {
+		if clusters[i].Namespace != clusters[j].Namespace {
+			return clusters[i].Namespace < clusters[j].Namespace
+		}
+		return clusters[i].Name < clusters[j].Name
+	})
 	return clusters, nil
 }
@@ -0,0 +1,234 @@
+/*
+ * Copyright (c) 2023 The nebula-contrib Authors.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *     http://www.apache.org/licenses/LICENSE-2.
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package logger
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/klog/v2"
+)
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// MaxVerbosity is the highest verbosity, the requests to kubernetes are logged from 6 like kubectl
+const MaxVerbosity = 9
+
+// Formats are the supported log formats
+var Formats = []string{FormatText, FormatJSON}
+
+const (
+	levelInfo    = "info"
+	levelWarning = "warning"
+	levelError   = "error"
+)
+
+// logger writes the diagnostics of ngctl, the results of the commands are written to stdout instead
+type logger struct {
+	mu        sync.Mutex
+	out       io.Writer
+	verbosity int
+	quiet     bool
+	format    string
+}
+
+var std = &logger{out: os.Stderr, format: FormatText}
+
+// Configure sets the output, the verbosity and the format of the logs, the info logs are dropped if quiet is true,
+// the verbosity is passed to klog and its logs are redirected to the logger, so the logs of the kubernetes clients
+// follow both of them
+func Configure(out io.Writer, verbosity int, quiet bool, format string) error {
+	if verbosity < 0 || verbosity > MaxVerbosity {
+		return fmt.Errorf("invalid verbosity %d, it should be between 0 and %d", verbosity, MaxVerbosity)
+	}
+	if format != FormatText && format != FormatJSON {
+		return fmt.Errorf("unsupported log format %s, one of %s", format, strings.Join(Formats, "|"))
+	}
+	std.mu.Lock()
+	std.out, std.verbosity, std.quiet, std.format = out, verbosity, quiet, format
+	std.mu.Unlock()
+
+	klog.SetLogger(logr.New(klogSink{}))
+	flags := flag.NewFlagSet("klog", flag.ContinueOnError)
+	klog.InitFlags(flags)
+	return flags.Set("v", strconv.Itoa(verbosity))
+}
+
+// klogSink writes the logs of klog, such as the requests logged by the kubernetes clients from -v=6, with the logger
+// instead of the text format of klog
+type klogSink struct {
+	keysAndValues []interface{}
+}
+
+func (s klogSink) Init(logr.RuntimeInfo) {}
+
+// Enabled always returns true since klog checks the verbosity before logging
+func (s klogSink) Enabled(int) bool {
+	return true
+}
+
+func (s klogSink) Info(_ int, msg string, keysAndValues ...interface{}) {
+	V(0).InfoS(strings.TrimSuffix(msg, "\n"), s.with(keysAndValues)...)
+}
+
+func (s klogSink) Error(err error, msg string, keysAndValues ...interface{}) {
+	keysAndValues = s.with(keysAndValues)
+	if err != nil {
+		keysAndValues = append(keysAndValues, "err", err)
+	}
+	std.log(levelError, strings.TrimSuffix(msg, "\n"), keysAndValues)
+}
+
+func (s klogSink) WithValues(keysAndValues ...interface{}) logr.LogSink {
+	return klogSink{keysAndValues: s.with(keysAndValues)}
+}
+
+func (s klogSink) WithName(string) logr.LogSink {
+	return s
+}
+
+// with returns the key and value pairs of the sink followed by keysAndValues
+func (s klogSink) with(keysAndValues []interface{}) []interface{} {
+	return append(append([]interface{}{}, s.keysAndValues...), keysAndValues...)
+}
+
+// Verbose logs the info messages if the verbosity is enabled
+type Verbose bool
+
+// V returns a Verbose which logs if the verbosity is at least level
+func V(level int) Verbose {
+	std.mu.Lock()
+	defer std.mu.Unlock()
+	return Verbose(!std.quiet && std.verbosity >= level)
+}
+
+// Enabled returns true if the verbosity is enabled
+func (v Verbose) Enabled() bool {
+	return bool(v)
+}
+
+func (v Verbose) Infof(format string, args ...interface{}) {
+	if v {
+		std.log(levelInfo, fmt.Sprintf(format, args...), nil)
+	}
+}
+
+// InfoS logs a structured message with the key and value pairs
+func (v Verbose) InfoS(msg string, keysAndValues ...interface{}) {
+	if v {
+		std.log(levelInfo, msg, keysAndValues)
+	}
+}
+
+// Infof logs a status message, it is dropped by --quiet
+func Infof(format string, args ...interface{}) {
+	V(0).Infof(format, args...)
+}
+
+// InfoS logs a structured status message with the key and value pairs, such as an event of a resource
+func InfoS(msg string, keysAndValues ...interface{}) {
+	V(0).InfoS(msg, keysAndValues...)
+}
+
+// Warningf logs a warning, it is kept by --quiet
+func Warningf(format string, args ...interface{}) {
+	std.log(levelWarning, fmt.Sprintf(format, args...), nil)
+}
+
+// WarningS logs a structured warning with the key and value pairs
+func WarningS(msg string, keysAndValues ...interface{}) {
+	std.log(levelWarning, msg, keysAndValues)
+}
+
+// Errorf logs an error
+func Errorf(format string, args ...interface{}) {
+	std.log(levelError, fmt.Sprintf(format, args...), nil)
+}
+
+func (l *logger) log(level, msg string, keysAndValues []interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var line string
+	if l.format == FormatJSON {
+		line = jsonLine(level, msg, keysAndValues)
+	} else {
+		line = textLine(level, msg, keysAndValues)
+	}
+	_, _ = io.WriteString(l.out, line+"\n")
+}
+
+// textLine renders the message as `[level: ]msg key=value ...`, the level of info messages is omitted
+func textLine(level, msg string, keysAndValues []interface{}) string {
+	var b strings.Builder
+	if level != levelInfo {
+		b.WriteString(level + ": ")
+	}
+	b.WriteString(msg)
+	for i := 0; i < len(keysAndValues); i += 2 {
+		value := "<missing>"
+		if i+1 < len(keysAndValues) {
+			value = fmt.Sprint(keysAndValues[i+1])
+		}
+		if value == "" || strings.ContainsAny(value, " \t\"=") {
+			value = strconv.Quote(value)
+		}
+		fmt.Fprintf(&b, " %v=%s", keysAndValues[i], value)
+	}
+	return b.String()
+}
+
+// jsonLine renders the message as a json object with the time, the level, the message and the key and value pairs
+func jsonLine(level, msg string, keysAndValues []interface{}) string {
+	var b strings.Builder
+	b.WriteString("{")
+	write := func(key string, value interface{}) {
+		k, _ := json.Marshal(key)
+		v, err := json.Marshal(value)
+		if err != nil {
+			v, _ = json.Marshal(fmt.Sprint(value))
+		}
+		if b.Len() > 1 {
+			b.WriteString(",")
+		}
+		b.Write(k)
+		b.WriteString(":")
+		b.Write(v)
+	}
+	write("time", time.Now().Format(time.RFC3339))
+	write("level", level)
+	write("msg", msg)
+	for i := 0; i < len(keysAndValues); i += 2 {
+		var value interface{} = "<missing>"
+		if i+1 < len(keysAndValues) {
+			value = keysAndValues[i+1]
+		}
+		if err, ok := value.(error); ok {
+			value = err.Error()
+		}
+		write(fmt.Sprint(keysAndValues[i]), value)
+	}
+	b.WriteString("}")
+	return b.String()
+}
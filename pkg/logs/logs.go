@@ -0,0 +1,371 @@
+/*
+ * Copyright (c) 2023 The nebula-contrib Authors.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *     http://www.apache.org/licenses/LICENSE-2.
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package logs
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/nebula-contrib/ngctl/pkg/glog"
+	"github.com/nebula-contrib/ngctl/pkg/logger"
+)
+
+// ErrNoPods is returned if no pods are selected and the logs are not followed
+var ErrNoPods = errors.New("no pods are found")
+
+// colors are the ansi colors of the pod names, they are assigned to the pods in turn
+var colors = []string{"\033[32m", "\033[33m", "\033[34m", "\033[35m", "\033[36m", "\033[91m"}
+
+const colorReset = "\033[0m"
+
+const (
+	// minWatchDelay is the delay before restarting a closed watch of pods
+	minWatchDelay = time.Second
+	// maxWatchDelay is the maximum delay before restarting a watch which keeps ending without any events
+	maxWatchDelay = 30 * time.Second
+)
+
+// Options are the options of the logs of the pods
+type Options struct {
+	// Follow streams the logs of the running pods and the pods created later until the context is done
+	Follow bool
+	// Since only returns the logs newer than the duration, zero means all logs
+	Since time.Duration
+	// Tail is the number of the recent lines of each pod, negative means all lines
+	Tail int64
+	// Previous returns the logs of the previous terminated container of each pod
+	Previous bool
+	// Grep only keeps the lines matching it if it is not nil
+	Grep *regexp.Regexp
+	// Color colors the pod names
+	Color bool
+	// Levels only keeps the glog records of the severities if it is not empty, the lines which are not in the glog
+	// format, such as the lines of a stack trace, follow the record before them
+	Levels []glog.Severity
+	// JSON writes each line as a json object of the parsed glog record with its pod
+	JSON bool
+}
+
+// entry is the json output of a line, the fields of the glog record are empty if the line is not in the glog format
+type entry struct {
+	Pod      string     `json:"pod"`
+	Severity string     `json:"severity,omitempty"`
+	Time     *time.Time `json:"time,omitempty"`
+	Thread   int        `json:"thread,omitempty"`
+	File     string     `json:"file,omitempty"`
+	Line     int        `json:"line,omitempty"`
+	Message  string     `json:"message"`
+}
+
+// Aggregator streams the logs of the pods selected by a label selector concurrently, each line is prefixed with the
+// name of its pod. when following, the pods created or restarted during a rollout are streamed as they start, and
+// the streams of the deleted pods end
+type Aggregator struct {
+	Client    kubernetes.Interface
+	Namespace string
+	Selector  string
+	Options   Options
+	Out       io.Writer
+
+	mu      sync.Mutex
+	colors  map[string]string
+	streams map[string]bool
+	wg      sync.WaitGroup
+	errs    []error
+}
+
+// Run streams the logs until all streams end, or until ctx is done if the logs are followed
+func (a *Aggregator) Run(ctx context.Context) error {
+	a.colors = map[string]string{}
+	a.streams = map[string]bool{}
+	pods := a.Client.CoreV1().Pods(a.Namespace)
+	list, err := pods.List(ctx, metav1.ListOptions{LabelSelector: a.Selector})
+	if err != nil {
+		return err
+	}
+	sort.Slice(list.Items, func(i, j int) bool {
+		return list.Items[i].Name < list.Items[j].Name
+	})
+	follow := a.Options.Follow && !a.Options.Previous
+	if len(list.Items) == 0 && !follow {
+		return ErrNoPods
+	}
+	for i := range list.Items {
+		pod := &list.Items[i]
+		// the pods which are not started yet are streamed by the watch when their containers are running
+		if follow && containerID(pod) == "" {
+			continue
+		}
+		a.start(ctx, pod, true)
+	}
+	if !follow {
+		a.wg.Wait()
+		return errors.Join(a.errs...)
+	}
+
+	err = a.watch(ctx, list.ResourceVersion)
+	a.wg.Wait()
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// watch starts the streams of the pods whose containers are started after resourceVersion until ctx is done. the
+// watch is restarted with a backoff when it is closed, and the pods are listed again if resourceVersion is expired
+func (a *Aggregator) watch(ctx context.Context, resourceVersion string) error {
+	pods := a.Client.CoreV1().Pods(a.Namespace)
+	delay := time.Duration(0)
+	for {
+		if delay > 0 {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(delay):
+			}
+		}
+		w, err := pods.Watch(ctx, metav1.ListOptions{LabelSelector: a.Selector, ResourceVersion: resourceVersion})
+		if err != nil {
+			return err
+		}
+		received := false
+		for closed := false; !closed; {
+			select {
+			case <-ctx.Done():
+				w.Stop()
+				return nil
+			case event, ok := <-w.ResultChan():
+				if !ok {
+					closed = true
+					break
+				}
+				if event.Type == watch.Error {
+					w.Stop()
+					closed = true
+					if err := apierrors.FromObject(event.Object); apierrors.IsResourceExpired(err) || apierrors.IsGone(err) {
+						logger.V(1).Infof("resource version %s of pods is expired, listing them again", resourceVersion)
+						resourceVersion = a.relist(ctx)
+					} else {
+						logger.Warningf("watch of pods: %v", err)
+					}
+					break
+				}
+				pod, ok := event.Object.(*corev1.Pod)
+				if !ok {
+					continue
+				}
+				received = true
+				resourceVersion = pod.ResourceVersion
+				switch event.Type {
+				case watch.Added, watch.Modified:
+					if containerID(pod) != "" {
+						a.start(ctx, pod, false)
+					}
+				case watch.Deleted:
+					logger.V(1).Infof("pod %s is deleted", pod.Name)
+				}
+			}
+		}
+		// the watch is closed by the server or failed, it is started again from the last resource version after a
+		// delay which grows while the watches end without any events
+		if received || delay == 0 {
+			delay = minWatchDelay
+		} else if delay *= 2; delay > maxWatchDelay {
+			delay = maxWatchDelay
+		}
+		logger.V(1).Infof("watch of pods is closed, restarting it in %s", delay)
+	}
+}
+
+// relist starts the streams of the running pods and returns the resource version of the list, the watch is started
+// from the current state if the pods cannot be listed
+func (a *Aggregator) relist(ctx context.Context) string {
+	list, err := a.Client.CoreV1().Pods(a.Namespace).List(ctx, metav1.ListOptions{LabelSelector: a.Selector})
+	if err != nil {
+		logger.Warningf("list pods: %v", err)
+		return ""
+	}
+	for i := range list.Items {
+		if containerID(&list.Items[i]) != "" {
+			a.start(ctx, &list.Items[i], false)
+		}
+	}
+	return list.ResourceVersion
+}
+
+// start streams the logs of the container of pod if it is not streamed yet, the tail and since options only apply
+// to the initial pods, the logs of the containers started later are streamed from the beginning
+func (a *Aggregator) start(ctx context.Context, pod *corev1.Pod, initial bool) {
+	if len(pod.Spec.Containers) == 0 {
+		return
+	}
+	key := string(pod.UID) + "/" + pod.Name + "/" + containerID(pod)
+	a.mu.Lock()
+	if a.streams[key] {
+		a.mu.Unlock()
+		return
+	}
+	a.streams[key] = true
+	a.mu.Unlock()
+
+	options := &corev1.PodLogOptions{
+		Container: pod.Spec.Containers[0].Name,
+		Follow:    a.Options.Follow && !a.Options.Previous,
+		Previous:  a.Options.Previous,
+	}
+	if initial {
+		if a.Options.Tail >= 0 {
+			tail := a.Options.Tail
+			options.TailLines = &tail
+		}
+		if a.Options.Since > 0 {
+			// the api only accepts whole seconds, a duration under one second is rounded up
+			seconds := int64(math.Ceil(a.Options.Since.Seconds()))
+			options.SinceSeconds = &seconds
+		}
+	} else {
+		logger.V(1).Infof("streaming the logs of pod %s", pod.Name)
+	}
+
+	// the colors are assigned before streaming so that the pods get the same colors in each run
+	prefix := a.prefix(pod.Name)
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		if err := a.stream(ctx, pod.Name, prefix, options); err != nil && ctx.Err() == nil {
+			err = fmt.Errorf("logs of pod %s: %w", pod.Name, err)
+			if options.Follow {
+				logger.Warningf("%v", err)
+				return
+			}
+			a.mu.Lock()
+			a.errs = append(a.errs, err)
+			a.mu.Unlock()
+		}
+	}()
+}
+
+func (a *Aggregator) stream(ctx context.Context, name, prefix string, options *corev1.PodLogOptions) error {
+	body, err := a.Client.CoreV1().Pods(a.Namespace).GetLogs(name, options).Stream(ctx)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	reader := bufio.NewReader(body)
+	// last is the severity of the last glog record of the pod, it is nil before the first one
+	var last *glog.Severity
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			line = strings.TrimRight(line, "\r\n")
+			record, ok := glog.Parse(line)
+			if ok {
+				last = &record.Severity
+			}
+			a.write(name, prefix, line, record, ok, last)
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// prefix returns the prefix of the lines of a pod, the pods are colored in turn
+func (a *Aggregator) prefix(name string) string {
+	if !a.Options.Color {
+		return "[" + name + "]"
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	color, ok := a.colors[name]
+	if !ok {
+		color = colors[len(a.colors)%len(colors)]
+		a.colors[name] = color
+	}
+	return color + "[" + name + "]" + colorReset
+}
+
+// write writes a line of a pod if it matches the filters, record is the parsed line if parsed is true, and severity
+// is the severity of the line or the record before it
+func (a *Aggregator) write(pod, prefix, line string, record glog.Record, parsed bool, severity *glog.Severity) {
+	if a.Options.Grep != nil && !a.Options.Grep.MatchString(line) {
+		return
+	}
+	if len(a.Options.Levels) > 0 && (severity == nil || !containsSeverity(a.Options.Levels, *severity)) {
+		return
+	}
+	text := prefix + " " + line
+	if a.Options.JSON {
+		e := entry{Pod: pod, Message: line}
+		if parsed {
+			e.Time, e.Thread, e.File, e.Line, e.Message = &record.Time, record.Thread, record.File, record.Line, record.Message
+		}
+		if severity != nil {
+			e.Severity = severity.String()
+		}
+		content, err := json.Marshal(e)
+		if err != nil {
+			return
+		}
+		text = string(content)
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	_, _ = fmt.Fprintln(a.Out, text)
+}
+
+func containsSeverity(severities []glog.Severity, severity glog.Severity) bool {
+	for _, s := range severities {
+		if s == severity {
+			return true
+		}
+	}
+	return false
+}
+
+// containerID identifies the running or terminated first container of pod by its restart count and id, it is empty
+// if the container is not started yet
+func containerID(pod *corev1.Pod) string {
+	if len(pod.Spec.Containers) == 0 {
+		return ""
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name != pod.Spec.Containers[0].Name {
+			continue
+		}
+		if status.State.Running != nil || status.State.Terminated != nil {
+			return fmt.Sprintf("%d/%s", status.RestartCount, status.ContainerID)
+		}
+	}
+	return ""
+}
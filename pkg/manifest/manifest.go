@@ -0,0 +1,129 @@
+/*
+ * Copyright (c) 2023 The nebula-contrib Authors.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *     http://www.apache.org/licenses/LICENSE-2.
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package manifest
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/vesoft-inc/nebula-operator/apis/apps/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+const Kind = "NebulaCluster"
+
+// Defaults are the default values of the nebula graph cluster spec filled by the server,
+// * matches any single segment of the field path
+var Defaults = map[string]interface{}{
+	"schedulerName":         "default-scheduler",
+	"imagePullPolicy":       "Always",
+	"*.version":             "latest",
+	"logRotate.rotate":      5,
+	"logRotate.size":        "200M",
+	"exporter.maxRequests":  40,
+	"sslCerts.serverCert":   "tls.crt",
+	"sslCerts.serverKey":    "tls.key",
+	"sslCerts.clientCert":   "tls.crt",
+	"sslCerts.clientKey":    "tls.key",
+	"sslCerts.caCert":       "ca.crt",
+	"sslCerts.clientCACert": "ca.crt",
+	"reference.name":        "statefulsets.apps",
+	"reference.version":     "v1",
+	"metad.service.type":    "ClusterIP",
+	"storaged.service.type": "ClusterIP",
+	"graphd.service.type":   "ClusterIP",
+}
+
+// Read reads the content of a manifest file, "-" means stdin, which is the input of the command rather than os.Stdin
+func Read(path string, stdin io.Reader) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(stdin)
+	}
+	return os.ReadFile(path)
+}
+
+// Decode decodes all the yaml or json documents in data, empty documents are skipped
+func Decode(data []byte) ([]*unstructured.Unstructured, error) {
+	var objects []*unstructured.Unstructured
+	reader := yaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(data)))
+	for {
+		doc, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+		content, err := yaml.ToJSON(doc)
+		if err != nil {
+			return nil, err
+		}
+		if bytes.Equal(bytes.TrimSpace(content), []byte("null")) {
+			continue
+		}
+		object := &unstructured.Unstructured{}
+		if err = object.UnmarshalJSON(content); err != nil {
+			return nil, err
+		}
+		objects = append(objects, object)
+	}
+	return objects, nil
+}
+
+// DecodeClusters decodes the nebula graph clusters in data, other kinds are rejected
+func DecodeClusters(data []byte) ([]*unstructured.Unstructured, error) {
+	objects, err := Decode(data)
+	if err != nil {
+		return nil, err
+	}
+	for _, object := range objects {
+		gvk := object.GroupVersionKind()
+		if gvk.GroupVersion() != v1alpha1.GroupVersion || gvk.Kind != Kind {
+			return nil, fmt.Errorf("unsupported kind %s of %s", gvk.String(), object.GetName())
+		}
+		if object.GetName() == "" {
+			return nil, errors.New("name of the nebula graph cluster is missing")
+		}
+	}
+	return objects, nil
+}
+
+// ToCluster converts an unstructured object to a nebula graph cluster
+func ToCluster(object *unstructured.Unstructured) (*v1alpha1.NebulaCluster, error) {
+	cluster := &v1alpha1.NebulaCluster{}
+	err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, cluster)
+	if err != nil {
+		return nil, err
+	}
+	return cluster, nil
+}
+
+// ToClusterStrict is the same as ToCluster but returns an error listing all the unknown fields
+func ToClusterStrict(object *unstructured.Unstructured) (*v1alpha1.NebulaCluster, error) {
+	cluster := &v1alpha1.NebulaCluster{}
+	err := runtime.DefaultUnstructuredConverter.FromUnstructuredWithValidation(object.Object, cluster, true)
+	if err != nil {
+		return nil, err
+	}
+	return cluster, nil
+}
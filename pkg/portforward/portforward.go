@@ -0,0 +1,211 @@
+/*
+ * Copyright (c) 2023 The nebula-contrib Authors.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *     http://www.apache.org/licenses/LICENSE-2.
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package portforward
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+
+	"github.com/nebula-contrib/ngctl/pkg/logger"
+)
+
+// DefaultAddress is the local address the ports are forwarded from by default
+const DefaultAddress = "127.0.0.1"
+
+// Port is a port of a pod to forward
+type Port struct {
+	// Name is the name of the port such as thrift or http
+	Name string
+	// Remote is the port of the pod
+	Remote int32
+	// Local is the local port, zero means the same port as Remote if it is free, otherwise a free port
+	Local int32
+}
+
+// Endpoint is a forwarded port
+type Endpoint struct {
+	Name   string `json:"name"`
+	Local  string `json:"local"`
+	Pod    string `json:"pod"`
+	Remote int32  `json:"remote"`
+}
+
+// Session forwards the ports of a pod until it is closed or ctx is done
+type Session struct {
+	Endpoints []Endpoint
+
+	stop chan struct{}
+	done chan error
+}
+
+// Close stops forwarding, it is safe to close a session more than once
+func (s *Session) Close() {
+	select {
+	case <-s.stop:
+	default:
+		close(s.stop)
+	}
+}
+
+// Wait waits until the session is closed or the forwarding fails
+func (s *Session) Wait() error {
+	return <-s.done
+}
+
+// Forward forwards the ports of the pod from the local address, it returns after the local ports are listened on.
+// the session is closed when ctx is done
+func Forward(ctx context.Context, config *rest.Config, clientSet kubernetes.Interface, namespace, pod, address string,
+	ports []Port) (*Session, error) {
+	if address == "" {
+		address = DefaultAddress
+	}
+	specs := make([]string, 0, len(ports))
+	for _, port := range ports {
+		specs = append(specs, Spec(address, port))
+	}
+
+	transport, upgrader, err := spdy.RoundTripperFor(config)
+	if err != nil {
+		return nil, err
+	}
+	req := clientSet.CoreV1().RESTClient().Post().
+		Resource("pods").Namespace(namespace).Name(pod).
+		SubResource("portforward")
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, req.URL())
+
+	session := &Session{stop: make(chan struct{}), done: make(chan error, 1)}
+	ready := make(chan struct{})
+	forwarder, err := portforward.NewOnAddresses(dialer, []string{address}, specs, session.stop, ready,
+		io.Discard, logWriter{})
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		session.done <- forwarder.ForwardPorts()
+	}()
+	select {
+	case <-ready:
+	case err = <-session.done:
+		return nil, fmt.Errorf("forward the ports of pod %s: %w", pod, err)
+	case <-ctx.Done():
+		session.Close()
+		return nil, ctx.Err()
+	}
+	go func() {
+		select {
+		case <-ctx.Done():
+			session.Close()
+		case <-session.stop:
+		}
+	}()
+
+	forwarded, err := forwarder.GetPorts()
+	if err != nil {
+		session.Close()
+		return nil, err
+	}
+	for i, port := range forwarded {
+		session.Endpoints = append(session.Endpoints, Endpoint{
+			Name:   ports[i].Name,
+			Local:  net.JoinHostPort(address, strconv.Itoa(int(port.Local))),
+			Pod:    pod,
+			Remote: int32(port.Remote),
+		})
+	}
+	return session, nil
+}
+
+// Spec returns the forwarding spec of the port, the local port is port.Local if it is not zero, otherwise the remote
+// port if it is free on address. the spec ":<remote>" is returned if the remote port is busy, so that a free port is
+// bound by the forwarder atomically and read back from its ports
+func Spec(address string, port Port) string {
+	if port.Local != 0 {
+		return fmt.Sprintf("%d:%d", port.Local, port.Remote)
+	}
+	listener, err := net.Listen("tcp", net.JoinHostPort(address, strconv.Itoa(int(port.Remote))))
+	if err != nil {
+		logger.V(1).Infof("local port %d is not available, a free port is used: %v", port.Remote, err)
+		return fmt.Sprintf(":%d", port.Remote)
+	}
+	_ = listener.Close()
+	return fmt.Sprintf("%d:%d", port.Remote, port.Remote)
+}
+
+// ServicePod returns a running pod selected by the service, the pods are sorted by name so that the first running
+// one is picked in each run
+func ServicePod(ctx context.Context, clientSet kubernetes.Interface, service *corev1.Service) (*corev1.Pod, error) {
+	if len(service.Spec.Selector) == 0 {
+		return nil, fmt.Errorf("service %s has no selector", service.Name)
+	}
+	pods, err := clientSet.CoreV1().Pods(service.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(service.Spec.Selector).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(pods.Items, func(i, j int) bool {
+		return pods.Items[i].Name < pods.Items[j].Name
+	})
+	for i := range pods.Items {
+		if pods.Items[i].Status.Phase == corev1.PodRunning && pods.Items[i].DeletionTimestamp == nil {
+			return &pods.Items[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no running pods of service %s are found", service.Name)
+}
+
+// TargetPort returns the port of pod the service port is routed to, the named target ports are looked up in the
+// container ports of pod
+func TargetPort(pod *corev1.Pod, port corev1.ServicePort) (int32, error) {
+	if port.TargetPort.StrVal != "" {
+		for _, container := range pod.Spec.Containers {
+			for _, containerPort := range container.Ports {
+				if containerPort.Name == port.TargetPort.StrVal {
+					return containerPort.ContainerPort, nil
+				}
+			}
+		}
+		return 0, fmt.Errorf("target port %s is not found in pod %s", port.TargetPort.StrVal, pod.Name)
+	}
+	if port.TargetPort.IntVal != 0 {
+		return port.TargetPort.IntVal, nil
+	}
+	if port.Port == 0 {
+		return 0, errors.New("port is not set")
+	}
+	return port.Port, nil
+}
+
+// logWriter writes the errors of the forwarded connections as warnings
+type logWriter struct{}
+
+func (logWriter) Write(p []byte) (int, error) {
+	logger.Warningf("%s", strings.TrimRight(string(p), "\r\n"))
+	return len(p), nil
+}
@@ -0,0 +1,124 @@
+/*
+ * Copyright (c) 2023 The nebula-contrib Authors.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *     http://www.apache.org/licenses/LICENSE-2.
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package printer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	FormatTable = ""
+	FormatWide  = "wide"
+	FormatJSON  = "json"
+	FormatYAML  = "yaml"
+	FormatName  = "name"
+)
+
+// Formats are the supported output formats, the table format is selected by an empty string,
+// the jsonpath, go-template and custom-columns formats are followed by their templates
+var Formats = []string{FormatWide, FormatJSON, FormatYAML, FormatName,
+	PrefixJSONPath + "...", PrefixGoTemplate + "...", PrefixCustomColumns + "..."}
+
+// Table is a table of the human-readable output
+type Table struct {
+	// Title is logged before the table if it is not empty
+	Title  string
+	Header table.Row
+	Rows   []table.Row
+	// WideColumns is the number of the trailing columns which are only printed in the wide format
+	WideColumns int
+}
+
+// Printable is the result of a command, it is marshaled as is in the json and yaml formats
+type Printable interface {
+	// Tables returns the tables of the table and wide formats
+	Tables() []Table
+	// Names returns the resource names in the form of kind/name
+	Names() []string
+}
+
+// Validate returns an error if format is not supported
+func Validate(format string) error {
+	switch format {
+	case FormatTable, FormatWide, FormatJSON, FormatYAML, FormatName:
+		return nil
+	}
+	if isTemplate(format) {
+		return validateTemplate(format)
+	}
+	return fmt.Errorf("unsupported output format %s, one of %s", format, strings.Join(Formats, "|"))
+}
+
+// Print prints object to w in format
+func Print(w io.Writer, format string, object Printable) error {
+	switch format {
+	case FormatTable, FormatWide:
+		for _, t := range object.Tables() {
+			Render(w, t, format == FormatWide)
+		}
+		return nil
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(object)
+	case FormatYAML:
+		content, err := yaml.Marshal(object)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(content)
+		return err
+	case FormatName:
+		for _, name := range object.Names() {
+			if _, err := fmt.Fprintln(w, name); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		if isTemplate(format) {
+			return printTemplate(w, format, object)
+		}
+		return Validate(format)
+	}
+}
+
+// Render renders a table to w, the wide columns are dropped unless wide is true
+func Render(w io.Writer, t Table, wide bool) {
+	if t.Title != "" {
+		fmt.Fprintf(w, "%s:\n", t.Title)
+	}
+	tw := table.NewWriter()
+	tw.SetOutputMirror(w)
+	if len(t.Header) > 0 {
+		tw.AppendHeader(trim(t.Header, t.WideColumns, wide))
+	}
+	for _, row := range t.Rows {
+		tw.AppendRow(trim(row, t.WideColumns, wide))
+	}
+	tw.Render()
+}
+
+func trim(row table.Row, wideColumns int, wide bool) table.Row {
+	if wide || wideColumns == 0 || len(row) < wideColumns {
+		return row
+	}
+	return row[:len(row)-wideColumns]
+}
@@ -0,0 +1,201 @@
+/*
+ * Copyright (c) 2023 The nebula-contrib Authors.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *     http://www.apache.org/licenses/LICENSE-2.
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package printer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+const (
+	PrefixJSONPath      = "jsonpath="
+	PrefixGoTemplate    = "go-template="
+	PrefixCustomColumns = "custom-columns="
+)
+
+// Source is implemented by a Printable whose raw objects can be evaluated by
+// the jsonpath, go-template and custom-columns formats
+type Source interface {
+	// Object is the object evaluated by jsonpath and go-template
+	Object() interface{}
+	// Objects are the objects evaluated by custom-columns, one row for each
+	Objects() []interface{}
+}
+
+// column is a column of the custom-columns format
+type column struct {
+	header string
+	path   *jsonpath.JSONPath
+}
+
+// isTemplate returns true if format is one of the jsonpath, go-template and custom-columns formats
+func isTemplate(format string) bool {
+	return strings.HasPrefix(format, PrefixJSONPath) ||
+		strings.HasPrefix(format, PrefixGoTemplate) ||
+		strings.HasPrefix(format, PrefixCustomColumns)
+}
+
+// validateTemplate parses the template of format so that it fails before requesting kubernetes
+func validateTemplate(format string) error {
+	switch {
+	case strings.HasPrefix(format, PrefixJSONPath):
+		_, err := parseJSONPath(strings.TrimPrefix(format, PrefixJSONPath))
+		return err
+	case strings.HasPrefix(format, PrefixGoTemplate):
+		_, err := parseGoTemplate(strings.TrimPrefix(format, PrefixGoTemplate))
+		return err
+	default:
+		_, err := parseColumns(strings.TrimPrefix(format, PrefixCustomColumns))
+		return err
+	}
+}
+
+func printTemplate(w io.Writer, format string, object Printable) error {
+	source, ok := object.(Source)
+	if !ok {
+		return fmt.Errorf("output format %s is not supported by this command", format)
+	}
+	switch {
+	case strings.HasPrefix(format, PrefixJSONPath):
+		path, err := parseJSONPath(strings.TrimPrefix(format, PrefixJSONPath))
+		if err != nil {
+			return err
+		}
+		data, err := toGeneric(source.Object())
+		if err != nil {
+			return err
+		}
+		if err = path.Execute(w, data); err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w)
+		return err
+	case strings.HasPrefix(format, PrefixGoTemplate):
+		tmpl, err := parseGoTemplate(strings.TrimPrefix(format, PrefixGoTemplate))
+		if err != nil {
+			return err
+		}
+		data, err := toGeneric(source.Object())
+		if err != nil {
+			return err
+		}
+		return tmpl.Execute(w, data)
+	default:
+		columns, err := parseColumns(strings.TrimPrefix(format, PrefixCustomColumns))
+		if err != nil {
+			return err
+		}
+		return printColumns(w, columns, source.Objects())
+	}
+}
+
+func printColumns(w io.Writer, columns []column, items []interface{}) error {
+	t := Table{}
+	for _, c := range columns {
+		t.Header = append(t.Header, c.header)
+	}
+	for _, item := range items {
+		data, err := toGeneric(item)
+		if err != nil {
+			return err
+		}
+		row := make(table.Row, 0, len(columns))
+		for _, c := range columns {
+			results, err := c.path.FindResults(data)
+			if err != nil {
+				return err
+			}
+			var values []string
+			for _, result := range results {
+				for _, value := range result {
+					values = append(values, fmt.Sprintf("%v", value.Interface()))
+				}
+			}
+			if len(values) == 0 {
+				row = append(row, "<none>")
+			} else {
+				row = append(row, strings.Join(values, ","))
+			}
+		}
+		t.Rows = append(t.Rows, row)
+	}
+	Render(w, t, true)
+	return nil
+}
+
+// parseJSONPath parses a jsonpath template, the braces can be omitted for a single expression such as .spec.nodeName
+func parseJSONPath(text string) (*jsonpath.JSONPath, error) {
+	if text == "" {
+		return nil, errors.New("jsonpath template is empty")
+	}
+	if !strings.Contains(text, "{") {
+		text = "{" + text + "}"
+	}
+	path := jsonpath.New("output").AllowMissingKeys(true)
+	if err := path.Parse(text); err != nil {
+		return nil, fmt.Errorf("invalid jsonpath template %q: %w", text, err)
+	}
+	return path, nil
+}
+
+func parseGoTemplate(text string) (*template.Template, error) {
+	if text == "" {
+		return nil, errors.New("go template is empty")
+	}
+	tmpl, err := template.New("output").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid go template %q: %w", text, err)
+	}
+	return tmpl, nil
+}
+
+// parseColumns parses the custom columns in the form of HEADER:.path,HEADER:.path
+func parseColumns(text string) ([]column, error) {
+	if text == "" {
+		return nil, errors.New("custom columns are empty")
+	}
+	var columns []column
+	for _, spec := range strings.Split(text, ",") {
+		parts := strings.SplitN(spec, ":", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid custom column %q, expect HEADER:.path", spec)
+		}
+		path, err := parseJSONPath(parts[1])
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, column{header: parts[0], path: path})
+	}
+	return columns, nil
+}
+
+// toGeneric converts object to the generic form of json, so the fields are addressed by their json names
+func toGeneric(object interface{}) (interface{}, error) {
+	data, err := json.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+	var generic interface{}
+	if err = json.Unmarshal(data, &generic); err != nil {
+		return nil, err
+	}
+	return generic, nil
+}
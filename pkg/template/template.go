@@ -0,0 +1,212 @@
+/*
+ * Copyright (c) 2023 The nebula-contrib Authors.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *     http://www.apache.org/licenses/LICENSE-2.
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package template
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/homedir"
+	"sigs.k8s.io/yaml"
+
+	"github.com/nebula-contrib/ngctl/pkg/cluster"
+	"github.com/nebula-contrib/ngctl/pkg/diff"
+	"github.com/nebula-contrib/ngctl/pkg/manifest"
+)
+
+const (
+	TemplatesPath = ".ngctl/templates"
+	templateExt   = ".yaml"
+	// DefaultStorageClass is the storage class of the volumes of the built-in profiles, a manifest names its storage
+	// class explicitly so that it passes ngctl validate and does not depend on the default of the kubernetes cluster
+	DefaultStorageClass = "standard"
+)
+
+// Profile is a built-in or user defined template
+type Profile struct {
+	Name    string `json:"name"`
+	BuiltIn bool   `json:"builtIn"`
+	Path    string `json:"path,omitempty"`
+}
+
+// Dir returns the directory of the user defined templates
+func Dir() string {
+	return path.Join(homedir.HomeDir(), TemplatesPath)
+}
+
+// Profiles returns all the profiles, a user defined template shadows the built-in one with the same name
+func Profiles() ([]Profile, error) {
+	profiles := map[string]Profile{}
+	for _, name := range cluster.PresetNames() {
+		profiles[name] = Profile{Name: name, BuiltIn: true}
+	}
+	entries, err := os.ReadDir(Dir())
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != templateExt {
+			continue
+		}
+		name := strings.TrimSuffix(entry.Name(), templateExt)
+		profiles[name] = Profile{Name: name, Path: filepath.Join(Dir(), entry.Name())}
+	}
+
+	result := make([]Profile, 0, len(profiles))
+	for _, profile := range profiles {
+		result = append(result, profile)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+// Load loads the nebula graph cluster of a profile
+func Load(profile, name, namespace string) (map[string]interface{}, error) {
+	file := filepath.Join(Dir(), profile+templateExt)
+	content, err := os.ReadFile(file)
+	if err == nil {
+		return loadFile(file, content, name, namespace)
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	option, ok := cluster.Presets[profile]
+	if !ok {
+		return nil, fmt.Errorf("profile %s is not found, put your own template at %s", profile, file)
+	}
+	option.Name, option.Namespace = name, namespace
+	if option.StorageClass == "" {
+		option.StorageClass = DefaultStorageClass
+	}
+	nc, err := cluster.Build(&option)
+	if err != nil {
+		return nil, err
+	}
+	object, err := runtime.DefaultUnstructuredConverter.ToUnstructured(nc)
+	if err != nil {
+		return nil, err
+	}
+	delete(object, "status")
+	diff.Prune(object, nil)
+	return object, nil
+}
+
+func loadFile(file string, content []byte, name, namespace string) (map[string]interface{}, error) {
+	objects, err := manifest.DecodeClusters(content)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template %s: %w", file, err)
+	}
+	if len(objects) != 1 {
+		return nil, fmt.Errorf("expect 1 nebula graph cluster in template %s, but got %d", file, len(objects))
+	}
+	object := objects[0]
+	object.SetName(name)
+	object.SetNamespace(namespace)
+	return object.Object, nil
+}
+
+// ParseSet parses an override in the form of path=value, value is parsed as yaml
+func ParseSet(set string) (string, interface{}, error) {
+	i := strings.Index(set, "=")
+	if i <= 0 {
+		return "", nil, fmt.Errorf("invalid override %q, expect path=value", set)
+	}
+	var value interface{}
+	if err := yaml.Unmarshal([]byte(set[i+1:]), &value); err != nil {
+		return "", nil, fmt.Errorf("invalid value of override %q: %w", set, err)
+	}
+	return set[:i], value, nil
+}
+
+// Set sets the field of object at path such as spec.storaged.dataVolumeClaims[0].storageClassName,
+// the missing maps and list elements are created, [*] sets the field of all the existing elements
+func Set(object map[string]interface{}, fieldPath string, value interface{}) error {
+	segments := strings.Split(fieldPath, ".")
+	var current interface{} = object
+	for i, segment := range segments {
+		key, index, err := parseSegment(segment)
+		if err != nil {
+			return fmt.Errorf("invalid path %s: %w", fieldPath, err)
+		}
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("invalid path %s: %s is not an object", fieldPath, strings.Join(segments[:i], "."))
+		}
+		last := i == len(segments)-1
+		if index == allIndex {
+			list, _ := m[key].([]interface{})
+			for j := range list {
+				segments[i] = fmt.Sprintf("%s[%d]", key, j)
+				if err = Set(object, strings.Join(segments, "."), value); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		if index < 0 {
+			if last {
+				m[key] = value
+				return nil
+			}
+			if _, ok := m[key].(map[string]interface{}); !ok {
+				m[key] = map[string]interface{}{}
+			}
+			current = m[key]
+			continue
+		}
+
+		list, _ := m[key].([]interface{})
+		for len(list) <= index {
+			list = append(list, map[string]interface{}{})
+		}
+		m[key] = list
+		if last {
+			list[index] = value
+			return nil
+		}
+		current = list[index]
+	}
+	return nil
+}
+
+// allIndex is the index of key[*]
+const allIndex = -2
+
+// parseSegment parses key, key[index] or key[*], index is -1 if it is absent
+func parseSegment(segment string) (string, int, error) {
+	start := strings.Index(segment, "[")
+	if start < 0 {
+		return segment, -1, nil
+	}
+	if !strings.HasSuffix(segment, "]") {
+		return "", 0, fmt.Errorf("invalid segment %s", segment)
+	}
+	if segment[start+1:len(segment)-1] == "*" {
+		return segment[:start], allIndex, nil
+	}
+	index, err := strconv.Atoi(segment[start+1 : len(segment)-1])
+	if err != nil || index < 0 {
+		return "", 0, fmt.Errorf("invalid index of segment %s", segment)
+	}
+	return segment[:start], index, nil
+}
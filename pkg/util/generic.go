@@ -15,27 +15,35 @@ package util
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
-	"log"
-	"reflect"
 
-	appsv1 "k8s.io/api/apps/v1"
-	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	"github.com/nebula-contrib/ngctl/pkg/logger"
 )
 
-// Resource is the generic type for kubernetes resource
-type Resource interface {
-	appsv1.Deployment | corev1.Service | corev1.Pod | corev1.ConfigMap
+// FieldManager is the field manager of the resources applied by ngctl
+const FieldManager = "ngctl"
+
+// Object is the generic type for kubernetes resource, such as *corev1.Pod, *batchv1.Job or *corev1.Secret
+type Object interface {
+	metav1.Object
+	runtime.Object
 }
 
-// ResourceInterface is the generic interface for kubernetes resource management
-type ResourceInterface[T Resource] interface {
-	Get(ctx context.Context, name string, opts metav1.GetOptions) (*T, error)
-	Create(ctx context.Context, resource *T, opts metav1.CreateOptions) (*T, error)
+// ResourceInterface is the generic interface for kubernetes resource management, it is implemented by the typed
+// clients such as clientSet.CoreV1().Secrets(namespace)
+type ResourceInterface[T Object] interface {
+	Get(ctx context.Context, name string, opts metav1.GetOptions) (T, error)
+	Create(ctx context.Context, resource T, opts metav1.CreateOptions) (T, error)
+	Update(ctx context.Context, resource T, opts metav1.UpdateOptions) (T, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (T, error)
 	Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error
-	Update(ctx context.Context, resource *T, opts metav1.UpdateOptions) (*T, error)
 }
 
 type Status int
@@ -50,85 +58,150 @@ var (
 	ErrConflict = errors.New("resource conflicted")
 )
 
-// Check checks target resource and returns status
-func Check[T Resource](ctx context.Context, si ResourceInterface[T], name, label string) Status {
-	resource, err := si.Get(ctx, name, metav1.GetOptions{})
+// Check checks target resource and returns status, the resource is conflicted if it is not labeled by label=name
+func Check[T Object](ctx context.Context, si ResourceInterface[T], name, label string) (Status, error) {
+	_, status, err := check(ctx, si, name, label)
+	return status, err
+}
 
+// check returns the target resource and its status, the resource is nil if it is not found
+func check[T Object](ctx context.Context, si ResourceInterface[T], name, label string) (T, Status, error) {
+	var none T
+	resource, err := si.Get(ctx, name, metav1.GetOptions{})
 	if apierrors.IsNotFound(err) {
-		return StatusNotFound
+		return none, StatusNotFound, nil
 	}
-	/*
-		resource.Labels / resource.GetLabels() are not available here
-		the following code will cause error:
-		if resource.GetLabels()[label] != name {
-		.....
-		}
-	*/
+	if err != nil {
+		return none, StatusNotFound, err
+	}
+	if resource.GetLabels()[label] != name {
+		return resource, StatusConflicted, nil
+	}
+	return resource, StatusAlready, nil
+}
 
-	// get labels from resource by reflection
-	labels, ok := reflect.ValueOf(resource).Elem().FieldByName("ObjectMeta").FieldByName("Labels").Interface().(map[string]string)
-	if !ok || labels[label] != name {
-		return StatusConflicted
+// kindOf returns the kind of a resource, the kinds of the typed objects are looked up in the client-go scheme
+// since their type meta is usually empty
+func kindOf(object runtime.Object) string {
+	if kind := object.GetObjectKind().GroupVersionKind().Kind; kind != "" {
+		return kind
+	}
+	kinds, _, err := scheme.Scheme.ObjectKinds(object)
+	if err != nil || len(kinds) == 0 {
+		return "Unknown"
 	}
-	return StatusAlready
+	return kinds[0].Kind
 }
 
-// RemoveResource removes a resource
-func RemoveResource[T Resource](ctx context.Context, si ResourceInterface[T], name, label string) error {
-	status := Check(ctx, si, name, label)
-	typ := reflect.TypeOf(new(T)).Elem().Name()
+// event logs an event of a resource with its kind, name and namespace
+func event(msg string, object Object, dryRun bool) {
+	keysAndValues := []interface{}{"kind", kindOf(object), "name", object.GetName(), "namespace", object.GetNamespace()}
+	if dryRun {
+		keysAndValues = append(keysAndValues, "dryRun", "server")
+	}
+	logger.InfoS(msg, keysAndValues...)
+}
+
+func conflicted(object Object) error {
+	logger.WarningS("resource is conflicted", "kind", kindOf(object), "name", object.GetName(), "namespace", object.GetNamespace())
+	return ErrConflict
+}
+
+func dryRunAll(dryRun bool) []string {
+	if dryRun {
+		return []string{metav1.DryRunAll}
+	}
+	return nil
+}
+
+// RemoveResource removes a resource, the resource which is not labeled by label=name is kept
+func RemoveResource[T Object](ctx context.Context, si ResourceInterface[T], name, label string) error {
+	resource, status, err := check(ctx, si, name, label)
+	if err != nil {
+		return err
+	}
 	if status == StatusNotFound {
 		return nil
 	} else if status == StatusConflicted {
-		log.Printf("Resource %s %s is conflicted\n", typ, name)
+		_ = conflicted(resource)
 		return nil
 	}
-	err := si.Delete(ctx, name, metav1.DeleteOptions{})
+	err = si.Delete(ctx, name, metav1.DeleteOptions{})
 	if err != nil {
 		return err
 	}
-	log.Printf("Resource %s %s is removed\n", typ, name)
+	event("resource is removed", resource, false)
 	return nil
 }
 
-// CreateResource creates a resource
-func CreateResource[T Resource](ctx context.Context, si ResourceInterface[T], elem *T, name, label string) error {
-	status := Check(ctx, si, name, label)
-	typ := reflect.TypeOf(elem).Elem().Name()
-	if status == StatusAlready {
-		_, err := si.Update(ctx, elem, metav1.UpdateOptions{})
+// CreateOrUpdate creates a resource labeled by label=name, or applies it with server-side apply if it is already
+// created by ngctl. only the fields of elem are updated, so the fields set by the server or the controllers, such as
+// the node name of a pod, are kept and the immutable resources can be updated as long as elem does not change them.
+// it is submitted with server dry run without being persisted if dryRun is true
+func CreateOrUpdate[T Object](ctx context.Context, si ResourceInterface[T], elem T, label string, dryRun bool) error {
+	_, status, err := check(ctx, si, elem.GetName(), label)
+	if err != nil {
 		return err
 	}
 	if status == StatusConflicted {
-		log.Printf("Resource %s %s is conflicted\n", typ, name)
-		return ErrConflict
+		return conflicted(elem)
 	}
-	_, err := si.Create(ctx, elem, metav1.CreateOptions{})
-	if err != nil {
+	if status == StatusNotFound {
+		if _, err = si.Create(ctx, elem, metav1.CreateOptions{FieldManager: FieldManager, DryRun: dryRunAll(dryRun)}); err != nil {
+			return err
+		}
+		event("resource is created", elem, dryRun)
+		return nil
+	}
+
+	// the fields of a resource created by ngctl are owned by the update operation of the same field manager, the
+	// ownership is forced to be taken over by the apply operation
+	if _, err = apply(ctx, si, elem, true, dryRun); err != nil {
 		return err
 	}
+	event("resource is updated", elem, dryRun)
 	return nil
 }
 
-func CreateOrUpdate[T Resource](ctx context.Context, si ResourceInterface[T], elem *T, name, label string) error {
-	status := Check(ctx, si, name, label)
-	typ := reflect.TypeOf(elem).Elem().Name()
-	if status == StatusAlready {
-		log.Printf("Resource %s %s is already created\n", typ, name)
-		_, err := si.Update(ctx, elem, metav1.UpdateOptions{})
-		if err != nil {
-			return err
-		}
-		return nil
+// Apply applies a resource labeled by label=name with server-side apply, the fields of elem are owned by ngctl and
+// the ones managed by others are kept. force takes the ownership of the conflicting fields
+func Apply[T Object](ctx context.Context, si ResourceInterface[T], elem T, label string, force, dryRun bool) (T, error) {
+	var none T
+	_, status, err := check(ctx, si, elem.GetName(), label)
+	if err != nil {
+		return none, err
 	}
 	if status == StatusConflicted {
-		log.Printf("Resource %s %s is conflicted\n", typ, name)
-		return ErrConflict
+		return none, conflicted(elem)
 	}
-	_, err := si.Create(ctx, elem, metav1.CreateOptions{})
+	result, err := apply(ctx, si, elem, force, dryRun)
 	if err != nil {
-		return err
+		return none, err
 	}
-	log.Printf("Resource %s %s is created\n", typ, name)
-	return nil
+	event("resource is applied", elem, dryRun)
+	return result, nil
+}
+
+// apply submits elem as an apply patch without checking the label
+func apply[T Object](ctx context.Context, si ResourceInterface[T], elem T, force, dryRun bool) (T, error) {
+	var none T
+	// the apply patch requires the apiVersion and the kind which are usually empty in the typed objects
+	object := elem.DeepCopyObject().(T)
+	if object.GetObjectKind().GroupVersionKind().Empty() {
+		kinds, _, err := scheme.Scheme.ObjectKinds(object)
+		if err != nil {
+			return none, err
+		}
+		object.GetObjectKind().SetGroupVersionKind(kinds[0])
+	}
+	object.SetResourceVersion("")
+	data, err := json.Marshal(object)
+	if err != nil {
+		return none, err
+	}
+	return si.Patch(ctx, object.GetName(), types.ApplyPatchType, data, metav1.PatchOptions{
+		FieldManager: FieldManager,
+		Force:        &force,
+		DryRun:       dryRunAll(dryRun),
+	})
 }
@@ -14,29 +14,110 @@
 package util
 
 import (
+	"time"
+
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 )
 
-func NewClientSet(kubeconfig string) (*kubernetes.Clientset, error) {
-	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+// KubeOptions are the options to load the kubernetes config with the standard loading rules:
+// the explicit kubeconfig file, the files in KUBECONFIG, ~/.kube/config and the in-cluster config in order
+type KubeOptions struct {
+	// KubeConfig is the path of the kubeconfig file, the loading rules are used if it is empty
+	KubeConfig string
+	// Context is the kubernetes context to use, the current context of the kubeconfig is used if it is empty
+	Context string
+	// User is the kubeconfig user to use
+	User string
+	// Impersonate is the user to impersonate
+	Impersonate       string
+	ImpersonateGroups []string
+
+	// QPS and Burst limit the requests to kubernetes, the defaults of client-go are used if they are zero
+	QPS   float32
+	Burst int
+	// Timeout is the timeout of a single request, zero means no timeout
+	Timeout time.Duration
+}
+
+// ClientConfig returns the client config of the options
+func (o *KubeOptions) ClientConfig() clientcmd.ClientConfig {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = o.KubeConfig
+	overrides := &clientcmd.ConfigOverrides{
+		CurrentContext: o.Context,
+		Context:        clientcmdapi.Context{AuthInfo: o.User},
+		AuthInfo: clientcmdapi.AuthInfo{
+			Impersonate:       o.Impersonate,
+			ImpersonateGroups: o.ImpersonateGroups,
+		},
+	}
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides)
+}
+
+// RESTConfig returns the rest config of the options
+func (o *KubeOptions) RESTConfig() (*rest.Config, error) {
+	config, err := o.ClientConfig().ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	if o.QPS > 0 {
+		config.QPS = o.QPS
+	}
+	if o.Burst > 0 {
+		config.Burst = o.Burst
+	}
+	if o.Timeout > 0 {
+		config.Timeout = o.Timeout
+	}
+	return config, nil
+}
+
+// CurrentContext returns the name of the kubernetes context in use, it is empty for the in-cluster config
+func (o *KubeOptions) CurrentContext() (string, error) {
+	if o.Context != "" {
+		return o.Context, nil
+	}
+	raw, err := o.ClientConfig().RawConfig()
+	if err != nil {
+		return "", err
+	}
+	return raw.CurrentContext, nil
+}
+
+// ClientSet returns the kubernetes client set of the options
+func (o *KubeOptions) ClientSet() (*kubernetes.Clientset, error) {
+	config, err := o.RESTConfig()
 	if err != nil {
 		return nil, err
 	}
-	clientSet, err := kubernetes.NewForConfig(config)
+	return kubernetes.NewForConfig(config)
+}
+
+// DynamicClient returns the dynamic client of the options
+func (o *KubeOptions) DynamicClient() (*dynamic.DynamicClient, error) {
+	config, err := o.RESTConfig()
 	if err != nil {
 		return nil, err
 	}
-	return clientSet, nil
+	return dynamic.NewForConfig(config)
 }
 
-func NewDynamicClient(kubeConfig string) (*dynamic.DynamicClient, error) {
-	config, err := clientcmd.BuildConfigFromFlags("", kubeConfig)
+func NewClientSet(kubeconfig string) (*kubernetes.Clientset, error) {
+	options := &KubeOptions{KubeConfig: kubeconfig}
+	clientSet, err := options.ClientSet()
 	if err != nil {
 		return nil, err
 	}
-	client, err := dynamic.NewForConfig(config)
+	return clientSet, nil
+}
+
+func NewDynamicClient(kubeConfig string) (*dynamic.DynamicClient, error) {
+	options := &KubeOptions{KubeConfig: kubeConfig}
+	client, err := options.DynamicClient()
 	if err != nil {
 		return nil, err
 	}
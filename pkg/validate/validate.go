@@ -0,0 +1,242 @@
+/*
+ * Copyright (c) 2023 The nebula-contrib Authors.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *     http://www.apache.org/licenses/LICENSE-2.
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package validate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/vesoft-inc/nebula-operator/apis/apps/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/nebula-contrib/ngctl/pkg/manifest"
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Issue is a problem found in a manifest
+type Issue struct {
+	File     string   `json:"file"`
+	Name     string   `json:"name,omitempty"`
+	Severity Severity `json:"severity"`
+	Field    string   `json:"field,omitempty"`
+	Message  string   `json:"message"`
+}
+
+func (i Issue) String() string {
+	location := i.File
+	if i.Name != "" {
+		location += ":" + i.Name
+	}
+	if i.Field != "" {
+		return fmt.Sprintf("%s: %s: %s: %s", location, i.Severity, i.Field, i.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", location, i.Severity, i.Message)
+}
+
+// Files returns the manifest files of path, the yaml and json files in it are returned if path is a directory
+func Files(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+	var files []string
+	err = filepath.WalkDir(path, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		switch strings.ToLower(filepath.Ext(p)) {
+		case ".yaml", ".yml", ".json":
+			files = append(files, p)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// File validates all the nebula graph clusters in a manifest file
+func File(file string, data []byte) []Issue {
+	objects, err := manifest.Decode(data)
+	if err != nil {
+		return []Issue{{File: file, Severity: SeverityError, Message: err.Error()}}
+	}
+	var issues []Issue
+	for _, object := range objects {
+		name := object.GetName()
+		gvk := object.GroupVersionKind()
+		if gvk.GroupVersion() != v1alpha1.GroupVersion || gvk.Kind != manifest.Kind {
+			issues = append(issues, Issue{File: file, Name: name, Severity: SeverityError,
+				Message: fmt.Sprintf("unsupported kind %s", gvk.String())})
+			continue
+		}
+		cluster, err := manifest.ToClusterStrict(object)
+		if strictErr, ok := runtime.AsStrictDecodingError(err); ok {
+			for _, unknown := range strictErr.Errors() {
+				issues = append(issues, Issue{File: file, Name: name, Severity: SeverityError, Message: unknown.Error()})
+			}
+			// the known fields are still decoded
+			cluster, err = manifest.ToCluster(object)
+		}
+		if err != nil {
+			issues = append(issues, Issue{File: file, Name: name, Severity: SeverityError, Message: err.Error()})
+			continue
+		}
+		for _, issue := range Cluster(cluster) {
+			issue.File = file
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+type component struct {
+	name string
+	spec *v1alpha1.ComponentSpec
+}
+
+// Cluster validates the content of a nebula graph cluster
+func Cluster(cluster *v1alpha1.NebulaCluster) []Issue {
+	v := &validator{name: cluster.Name}
+	if cluster.Name == "" {
+		v.error("metadata.name", "name is required")
+	}
+
+	spec := cluster.Spec
+	var components []component
+	if spec.Metad == nil {
+		v.error("spec.metad", "metad is required")
+	} else {
+		components = append(components, component{"metad", &spec.Metad.ComponentSpec})
+		v.storageClaim("spec.metad.dataVolumeClaim", spec.Metad.DataVolumeClaim)
+		v.logClaim("spec.metad.logVolumeClaim", spec.Metad.LogVolumeClaim)
+		if replicas := spec.Metad.Replicas; replicas != nil && *replicas%2 == 0 {
+			v.warning("spec.metad.replicas", fmt.Sprintf("metad replicas %d is even, it tolerates no more failures than %d replicas", *replicas, *replicas-1))
+		}
+	}
+	if spec.Storaged == nil {
+		v.error("spec.storaged", "storaged is required")
+	} else {
+		components = append(components, component{"storaged", &spec.Storaged.ComponentSpec})
+		if len(spec.Storaged.DataVolumeClaims) == 0 {
+			v.error("spec.storaged.dataVolumeClaims", "data volume claims are required")
+		}
+		for i := range spec.Storaged.DataVolumeClaims {
+			v.storageClaim(fmt.Sprintf("spec.storaged.dataVolumeClaims[%d]", i), &spec.Storaged.DataVolumeClaims[i])
+		}
+		v.logClaim("spec.storaged.logVolumeClaim", spec.Storaged.LogVolumeClaim)
+		if replicas := spec.Storaged.Replicas; replicas != nil && *replicas < 3 {
+			v.warning("spec.storaged.replicas", fmt.Sprintf("storaged with %d replicas can not keep 3 copies of data", *replicas))
+		}
+	}
+	if spec.Graphd == nil {
+		v.error("spec.graphd", "graphd is required")
+	} else {
+		components = append(components, component{"graphd", &spec.Graphd.ComponentSpec})
+		v.logClaim("spec.graphd.logVolumeClaim", spec.Graphd.LogVolumeClaim)
+	}
+
+	versions := map[string]struct{}{}
+	for _, c := range components {
+		prefix := "spec." + c.name
+		if c.spec.Replicas == nil {
+			v.error(prefix+".replicas", "replicas is required")
+		}
+		if c.spec.Image == "" {
+			v.error(prefix+".image", "image is required")
+		}
+		if c.spec.Version == "" {
+			v.error(prefix+".version", "version is required")
+		} else {
+			versions[c.spec.Version] = struct{}{}
+		}
+		v.resources(prefix+".resources", c.spec.Resources)
+	}
+	if len(versions) > 1 {
+		var parts []string
+		for _, c := range components {
+			if c.spec.Version != "" {
+				parts = append(parts, fmt.Sprintf("%s %s", c.name, c.spec.Version))
+			}
+		}
+		v.warning("spec", "mismatched component versions: "+strings.Join(parts, ", "))
+	}
+	return v.issues
+}
+
+type validator struct {
+	name   string
+	issues []Issue
+}
+
+func (v *validator) error(field, message string) {
+	v.issues = append(v.issues, Issue{Name: v.name, Severity: SeverityError, Field: field, Message: message})
+}
+
+func (v *validator) warning(field, message string) {
+	v.issues = append(v.issues, Issue{Name: v.name, Severity: SeverityWarning, Field: field, Message: message})
+}
+
+func (v *validator) storageClaim(field string, claim *v1alpha1.StorageClaim) {
+	if claim == nil {
+		v.error(field, "data volume claim is required")
+		return
+	}
+	v.claimContent(field, claim)
+}
+
+func (v *validator) logClaim(field string, claim *v1alpha1.StorageClaim) {
+	if claim == nil {
+		v.warning(field, "log volume claim is not set, logs are lost when the pod is recreated")
+		return
+	}
+	v.claimContent(field, claim)
+}
+
+func (v *validator) claimContent(field string, claim *v1alpha1.StorageClaim) {
+	if storage, ok := claim.Resources.Requests[corev1.ResourceStorage]; !ok || storage.IsZero() {
+		v.error(field+".resources.requests.storage", "storage size is required")
+	}
+	if claim.StorageClassName == nil || *claim.StorageClassName == "" {
+		v.error(field+".storageClassName", "storage class is required")
+	}
+}
+
+func (v *validator) resources(field string, resources *corev1.ResourceRequirements) {
+	if resources == nil {
+		v.warning(field, "resources are not set")
+		return
+	}
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		request, hasRequest := resources.Requests[name]
+		limit, hasLimit := resources.Limits[name]
+		if hasRequest && hasLimit && limit.Cmp(request) < 0 {
+			v.error(fmt.Sprintf("%s.limits.%s", field, name),
+				fmt.Sprintf("limit %s is less than request %s", limit.String(), request.String()))
+		}
+	}
+}
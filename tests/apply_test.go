@@ -0,0 +1,33 @@
+/*
+ * Copyright (c) 2023 The nebula-contrib Authors.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *     http://www.apache.org/licenses/LICENSE-2.
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package tests
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestApplyStdin(t *testing.T) {
+	newFakeFactory(t)
+	// the manifest is read from the input of the command and printed with the namespace filled in
+	out, err := runWithInput(t, scaledManifest, "apply", "-f", "-", "--dry-run=client", "-n", "prod")
+	if err != nil {
+		t.Fatalf("run apply error: %v", err)
+	}
+	for _, field := range []string{"name: nebula", "namespace: prod", "replicas: 5"} {
+		if !strings.Contains(out, field) {
+			t.Errorf("expect %s in the manifest, but got\n%s", field, out)
+		}
+	}
+}
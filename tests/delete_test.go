@@ -0,0 +1,103 @@
+/*
+ * Copyright (c) 2023 The nebula-contrib Authors.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *     http://www.apache.org/licenses/LICENSE-2.
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package tests
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/nebula-contrib/ngctl/pkg/cluster"
+	"github.com/nebula-contrib/ngctl/pkg/config"
+)
+
+func TestDeletePurgeData(t *testing.T) {
+	f := newFakeFactory(t)
+	ctx := context.Background()
+	// the dynamically provisioned PVs do not carry the labels of their claims
+	for _, pv := range []*corev1.PersistentVolume{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "pvc-retained"},
+			Spec:       corev1.PersistentVolumeSpec{PersistentVolumeReclaimPolicy: corev1.PersistentVolumeReclaimRetain},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "pvc-provisioned"},
+			Spec:       corev1.PersistentVolumeSpec{PersistentVolumeReclaimPolicy: corev1.PersistentVolumeReclaimDelete},
+		},
+	} {
+		if _, err := f.Typed.CoreV1().PersistentVolumes().Create(ctx, pv, metav1.CreateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for component, volume := range map[string]string{cluster.Metad: "pvc-retained", cluster.Storaged: "pvc-provisioned"} {
+		pvc := &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: volumeClaimName(component), Namespace: testNamespace, Labels: componentLabels(component)},
+			Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: volume},
+		}
+		if _, err := f.Typed.CoreV1().PersistentVolumeClaims(testNamespace).Create(ctx, pvc, metav1.CreateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out, err := run(t, "delete", testCluster, "--purge-data", "--yes")
+	if err != nil {
+		t.Fatalf("run delete error: %v", err)
+	}
+	for _, row := range []string{"pvc-retained", "pvc-provisioned", "delete by provisioner"} {
+		if !strings.Contains(out, row) {
+			t.Errorf("expect %s in the preview, but got\n%s", row, out)
+		}
+	}
+	if _, err = f.Typed.CoreV1().PersistentVolumes().Get(ctx, "pvc-retained", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expect the retained PV is deleted, but got %v", err)
+	}
+	// the PV with the Delete reclaim policy is left to the provisioner
+	if _, err = f.Typed.CoreV1().PersistentVolumes().Get(ctx, "pvc-provisioned", metav1.GetOptions{}); err != nil {
+		t.Errorf("expect the provisioned PV is left to the provisioner, but got %v", err)
+	}
+	pvcs, err := f.Typed.CoreV1().PersistentVolumeClaims(testNamespace).List(ctx, metav1.ListOptions{})
+	if err != nil || len(pvcs.Items) != 0 {
+		t.Errorf("expect the PVCs are deleted, but got %v %v", pvcs, err)
+	}
+}
+
+func TestDeleteContexts(t *testing.T) {
+	newFakeFactory(t)
+	// the clusters of the same name in different kubernetes contexts have their own contexts
+	for _, c := range []config.Context{
+		{Name: "nebula", Cluster: testCluster, Namespace: testNamespace, KubeContext: "kind-a"},
+		{Name: "kind-b/default/nebula", Cluster: testCluster, Namespace: testNamespace, KubeContext: "kind-b"},
+	} {
+		if err := config.UseContext(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := run(t, "delete", testCluster, "--yes", "--context", "kind-a"); err != nil {
+		t.Fatalf("run delete error: %v", err)
+	}
+	conf, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := conf.Context("nebula"); ok {
+		t.Errorf("expect the context of the deleted cluster is removed")
+	}
+	if _, ok := conf.Context("kind-b/default/nebula"); !ok {
+		t.Errorf("expect the context of the cluster in kubernetes context kind-b is kept, but got %v", conf.Contexts)
+	}
+}
@@ -0,0 +1,158 @@
+/*
+ * Copyright (c) 2023 The nebula-contrib Authors.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *     http://www.apache.org/licenses/LICENSE-2.
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package tests
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vesoft-inc/nebula-operator/apis/apps/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/nebula-contrib/ngctl/pkg/cluster"
+	"github.com/nebula-contrib/ngctl/pkg/factory"
+)
+
+func TestDescribe(t *testing.T) {
+	t.Run("cluster", func(t *testing.T) {
+		f := newFakeFactory(t)
+		createEvents(t, f,
+			fakeEvent("nebula.1", "NebulaCluster", testCluster, corev1.EventTypeNormal, "ClusterReady", "cluster is ready", 1, 10*time.Minute))
+		out, err := run(t, "-c", testCluster, "describe")
+		if err != nil {
+			t.Fatalf("run describe error: %v", err)
+		}
+		assertGolden(t, "describe-cluster", out)
+	})
+	t.Run("cluster without optional fields", func(t *testing.T) {
+		f := newFakeFactory(t)
+		// the log volumes, the resources and the replicas are optional in the spec
+		resource := v1alpha1.GroupVersion.WithResource("nebulaclusters")
+		clusters := f.Dynamic.Resource(resource).Namespace(testNamespace)
+		nc, err := clusters.Get(context.Background(), testCluster, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, component := range []string{cluster.Graphd, cluster.Metad, cluster.Storaged} {
+			for _, field := range []string{"logVolumeClaim", "resources", "replicas", "dataVolumeClaim"} {
+				unstructured.RemoveNestedField(nc.Object, "spec", component, field)
+			}
+		}
+		if _, err = clusters.Update(context.Background(), nc, metav1.UpdateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+		out, err := run(t, "-c", testCluster, "describe")
+		if err != nil {
+			t.Fatalf("run describe error: %v", err)
+		}
+		assertGolden(t, "describe-cluster-minimal", out)
+		out, err = run(t, "-c", testCluster, "info")
+		if err != nil {
+			t.Fatalf("run info error: %v", err)
+		}
+		assertGolden(t, "info-minimal", out)
+	})
+	t.Run("pod", func(t *testing.T) {
+		f := newFakeFactory(t)
+		pod := fakePod(cluster.Storaged, 3)
+		pod.Status.Phase = corev1.PodRunning
+		pod.Status.Conditions = []corev1.PodCondition{
+			{Type: corev1.PodScheduled, Status: corev1.ConditionTrue, LastTransitionTime: metav1.NewTime(time.Now().Add(-time.Hour))},
+			{Type: corev1.PodReady, Status: corev1.ConditionFalse, Reason: "ContainersNotReady",
+				Message: "containers with unready status: [storaged]", LastTransitionTime: metav1.NewTime(time.Now().Add(-15 * time.Minute))},
+		}
+		pod.Status.ContainerStatuses[0].Ready = false
+		pod.Status.ContainerStatuses[0].RestartCount = 4
+		pod.Status.ContainerStatuses[0].State = corev1.ContainerState{
+			Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff", Message: "back-off 1m20s restarting failed container"},
+		}
+		pod.Status.ContainerStatuses[0].LastTerminationState = corev1.ContainerState{
+			Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137, FinishedAt: metav1.NewTime(time.Now().Add(-12 * time.Minute))},
+		}
+		if _, err := f.Typed.CoreV1().Pods(testNamespace).Update(context.Background(), pod, metav1.UpdateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+		createEvents(t, f,
+			fakeEvent("storaged.1", "Pod", pod.Name, corev1.EventTypeWarning, "FailedScheduling",
+				"0/1 nodes are available: 1 Insufficient memory.", 1, time.Hour),
+			fakeEvent("storaged.2", "Pod", pod.Name, corev1.EventTypeWarning, "Unhealthy",
+				"Liveness probe failed: Get \"http://10.0.0.3:19779/status\": connection refused", 3, 30*time.Minute),
+			fakeEvent("storaged.3", "Pod", pod.Name, corev1.EventTypeWarning, "BackOff",
+				"Back-off restarting failed container storaged", 5, 50*time.Minute),
+			fakeEvent("graphd.1", "Pod", testCluster+"-graphd-0", corev1.EventTypeWarning, "Unhealthy",
+				"Readiness probe failed", 1, 20*time.Minute),
+		)
+		out, err := run(t, "-c", testCluster, "describe", "storaged-0")
+		if err != nil {
+			t.Fatalf("run describe error: %v", err)
+		}
+		assertGolden(t, "describe-storaged", out)
+
+		out, err = run(t, "-c", testCluster, "describe", "graphd", "-o", "json")
+		if err != nil {
+			t.Fatalf("run describe error: %v", err)
+		}
+		if !strings.Contains(out, `"name": "nebula-graphd-0"`) || !strings.Contains(out, "Readiness probe failed") {
+			t.Errorf("expect the graphd pod and its event in the json output, but got %s", out)
+		}
+	})
+	t.Run("errors", func(t *testing.T) {
+		newFakeFactory(t)
+		for _, c := range []struct {
+			args []string
+			err  string
+		}{
+			{args: []string{"describe", "storaged-2"}, err: "pod nebula-storaged-2 is not found, the storaged pods are storaged-0"},
+			{args: []string{"describe", "console"}, err: "unsupported component console"},
+			{args: []string{"describe", "cluster", "graphd"}, err: "accepts at most 1 arg"},
+		} {
+			_, err := run(t, append([]string{"-c", testCluster}, c.args...)...)
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Errorf("run %s, expect error %q, but got %v", strings.Join(c.args, " "), c.err, err)
+			}
+		}
+	})
+}
+
+func createEvents(t *testing.T, f *factory.Fake, events ...*corev1.Event) {
+	t.Helper()
+	for _, event := range events {
+		if _, err := f.Typed.CoreV1().Events(testNamespace).Create(context.Background(), event, metav1.CreateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// fakeEvent returns an event of the object which occurred count times in the last period
+func fakeEvent(name, kind, object, typ, reason, message string, count int32, period time.Duration) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+		InvolvedObject: corev1.ObjectReference{
+			Kind:      kind,
+			Namespace: testNamespace,
+			Name:      object,
+		},
+		Type:           typ,
+		Reason:         reason,
+		Message:        message,
+		Count:          count,
+		FirstTimestamp: metav1.NewTime(time.Now().Add(-period)),
+		LastTimestamp:  metav1.NewTime(time.Now().Add(-period / time.Duration(count))),
+		Source:         corev1.EventSource{Component: "kubelet"},
+	}
+}
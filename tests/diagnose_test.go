@@ -0,0 +1,50 @@
+/*
+ * Copyright (c) 2023 The nebula-contrib Authors.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *     http://www.apache.org/licenses/LICENSE-2.
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package tests
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/nebula-contrib/ngctl/pkg/cluster"
+)
+
+func TestPodIssue(t *testing.T) {
+	initStatus := func(exitCode int32, reason string) corev1.ContainerStatus {
+		return corev1.ContainerStatus{
+			Name:  "init",
+			State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: exitCode, Reason: reason}},
+		}
+	}
+	for _, c := range []struct {
+		name     string
+		init     corev1.ContainerStatus
+		expected string
+	}{
+		{name: "completed init container", init: initStatus(0, "Completed"), expected: ""},
+		{name: "failed init container", init: initStatus(1, "Error"), expected: "Error: exit code 1"},
+	} {
+		pod := fakePod(cluster.Storaged, 1)
+		// the spare capacity shows whether the init container statuses of the pod are written
+		pod.Status.InitContainerStatuses = make([]corev1.ContainerStatus, 1, 2)
+		pod.Status.InitContainerStatuses[0] = c.init
+		if issue := cluster.PodIssue(pod); issue != c.expected {
+			t.Errorf("%s: expect issue %q, but got %q", c.name, c.expected, issue)
+		}
+		if spare := pod.Status.InitContainerStatuses[:2][1]; spare.Name != "" {
+			t.Errorf("%s: expect the statuses of the pod are not modified, but got %+v", c.name, spare)
+		}
+	}
+}
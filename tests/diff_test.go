@@ -0,0 +1,74 @@
+/*
+ * Copyright (c) 2023 The nebula-contrib Authors.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *     http://www.apache.org/licenses/LICENSE-2.
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package tests
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nebula-contrib/ngctl/cmd"
+)
+
+const scaledManifest = `apiVersion: apps.nebula-graph.io/v1alpha1
+kind: NebulaCluster
+metadata:
+  name: nebula
+spec:
+  graphd:
+    replicas: 5
+`
+
+func TestDiffExitCode(t *testing.T) {
+	newFakeFactory(t)
+
+	t.Run("differences", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "nebula.yaml")
+		if err := os.WriteFile(file, []byte(scaledManifest), 0600); err != nil {
+			t.Fatal(err)
+		}
+		out, err := run(t, "diff", "-f", file, "--format", "structured")
+		if !errors.Is(err, cmd.ErrDiffer) || cmd.ExitCode(err) != 1 {
+			t.Fatalf("expect the specs are different with exit code 1, but got %v, exit code %d", err, cmd.ExitCode(err))
+		}
+		if !strings.Contains(out, "graphd.replicas") {
+			t.Errorf("expect the differences of graphd replicas, but got %s", out)
+		}
+	})
+	t.Run("stdin", func(t *testing.T) {
+		out, err := runWithInput(t, scaledManifest, "diff", "-f", "-", "--format", "structured")
+		if !errors.Is(err, cmd.ErrDiffer) || !strings.Contains(out, "graphd.replicas") {
+			t.Errorf("expect the differences of the manifest read from stdin, but got %v\n%s", err, out)
+		}
+	})
+	t.Run("errors", func(t *testing.T) {
+		for _, args := range [][]string{
+			{"diff", "nebula", "missing"},
+			{"diff", "nebula", "nebula", "--format", "side-by-side"},
+		} {
+			_, err := run(t, args...)
+			if err == nil || errors.Is(err, cmd.ErrDiffer) || cmd.ExitCode(err) != 2 {
+				t.Errorf("run %s, expect an error with exit code 2, but got %v, exit code %d",
+					strings.Join(args, " "), err, cmd.ExitCode(err))
+			}
+		}
+	})
+	t.Run("same", func(t *testing.T) {
+		if _, err := run(t, "diff", "nebula", "nebula"); err != nil || cmd.ExitCode(err) != 0 {
+			t.Errorf("expect no differences, but got %v", err)
+		}
+	})
+}
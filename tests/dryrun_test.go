@@ -0,0 +1,56 @@
+/*
+ * Copyright (c) 2023 The nebula-contrib Authors.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *     http://www.apache.org/licenses/LICENSE-2.
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDryRun(t *testing.T) {
+	t.Run("client", func(t *testing.T) {
+		f := newFakeFactory(t)
+		out, err := run(t, "studio", "install", "--name", "studio-dry-run", "--dry-run=client")
+		if err != nil {
+			t.Fatalf("run studio install error: %v", err)
+		}
+		assertGolden(t, "studio-install-dry-run", out)
+		if actions := f.Typed.(*fake.Clientset).Actions(); len(actions) != 0 {
+			t.Errorf("expect no requests in client dry run, but got %v", actions)
+		}
+	})
+	t.Run("server", func(t *testing.T) {
+		newFakeFactory(t)
+		out, err := run(t, "studio", "install", "--name", "studio-dry-run", "--dry-run=server")
+		if err != nil {
+			t.Fatalf("run studio install error: %v", err)
+		}
+		if strings.Count(out, "dryRun=server") != 2 {
+			t.Errorf("expect 2 events of server dry run, but got %q", out)
+		}
+	})
+	t.Run("unsupported", func(t *testing.T) {
+		newFakeFactory(t)
+		for _, args := range [][]string{
+			{"delete", testCluster, "--dry-run=client"},
+			{"studio", "install", "--dry-run=all"},
+		} {
+			if _, err := run(t, args...); err == nil {
+				t.Errorf("expect an error of %s", strings.Join(args, " "))
+			}
+		}
+	})
+}
@@ -0,0 +1,117 @@
+/*
+ * Copyright (c) 2023 The nebula-contrib Authors.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *     http://www.apache.org/licenses/LICENSE-2.
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package tests
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vesoft-inc/nebula-operator/apis/apps/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/nebula-contrib/ngctl/pkg/factory"
+)
+
+const lastApplied = "kubectl.kubernetes.io/last-applied-configuration"
+
+// setEditor sets $EDITOR to a shell script which edits the file in place
+func setEditor(t *testing.T, script string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "editor.sh")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\nset -e\n"+script+"\n"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("EDITOR", path)
+}
+
+// clusterResource returns the nebula graph clusters of the test namespace in the fake dynamic client
+func clusterResource(f *factory.Fake) dynamic.ResourceInterface {
+	return f.Dynamic.Resource(v1alpha1.GroupVersion.WithResource("nebulaclusters")).Namespace(testNamespace)
+}
+
+func TestEdit(t *testing.T) {
+	t.Run("hidden annotations", func(t *testing.T) {
+		f := newFakeFactory(t)
+		clusters := clusterResource(f)
+		live, err := clusters.Get(context.Background(), testCluster, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		live.SetAnnotations(map[string]string{lastApplied: "{}", "owner": "dba"})
+		if _, err = clusters.Update(context.Background(), live, metav1.UpdateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+		setEditor(t, `sed -i 's/owner: dba/owner: ops/' "$1"`)
+		if _, err = run(t, "-c", testCluster, "edit"); err != nil {
+			t.Fatalf("run edit error: %v", err)
+		}
+		edited, err := clusters.Get(context.Background(), testCluster, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		annotations := edited.GetAnnotations()
+		if annotations[lastApplied] != "{}" || annotations["owner"] != "ops" {
+			t.Errorf("expect the last applied configuration is kept and owner is edited, but got %v", annotations)
+		}
+	})
+	t.Run("zero values", func(t *testing.T) {
+		f := newFakeFactory(t)
+		clusters := clusterResource(f)
+		live, err := clusters.Get(context.Background(), testCluster, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the values set explicitly are kept even if they are zero values
+		if err = unstructured.SetNestedField(live.Object, false, "spec", "enablePVReclaim"); err != nil {
+			t.Fatal(err)
+		}
+		if err = unstructured.SetNestedField(live.Object, "", "spec", "metad", "dataVolumeClaim", "storageClassName"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = clusters.Update(context.Background(), live, metav1.UpdateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+		setEditor(t, `sed -i 's/replicas: 3/replicas: 5/' "$1"`)
+		if _, err = run(t, "-c", testCluster, "edit"); err != nil {
+			t.Fatalf("run edit error: %v", err)
+		}
+		edited, err := clusters.Get(context.Background(), testCluster, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if replicas, _, _ := unstructured.NestedInt64(edited.Object, "spec", "storaged", "replicas"); replicas != 5 {
+			t.Errorf("expect storaged is scaled to 5, but got %d", replicas)
+		}
+		if reclaim, ok, _ := unstructured.NestedBool(edited.Object, "spec", "enablePVReclaim"); !ok || reclaim {
+			t.Errorf("expect enablePVReclaim: false is kept, but got %v %v", reclaim, ok)
+		}
+		if class, ok, _ := unstructured.NestedString(edited.Object, "spec", "metad", "dataVolumeClaim", "storageClassName"); !ok || class != "" {
+			t.Errorf("expect the empty storage class is kept, but got %q %v", class, ok)
+		}
+	})
+	t.Run("unchanged invalid content", func(t *testing.T) {
+		newFakeFactory(t)
+		// the invalid field is added once, the reopened file is saved as it is
+		setEditor(t, `grep -q unknownField "$1" || sed -i 's/^spec:/spec:\n  unknownField: 1/' "$1"`)
+		_, err := run(t, "-c", testCluster, "edit")
+		if err == nil || !strings.Contains(err.Error(), "the invalid changes are not fixed") {
+			t.Errorf("expect the edit is aborted, but got %v", err)
+		}
+	})
+}
@@ -0,0 +1,62 @@
+/*
+ * Copyright (c) 2023 The nebula-contrib Authors.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *     http://www.apache.org/licenses/LICENSE-2.
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package tests
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/nebula-contrib/ngctl/pkg/exec"
+)
+
+func TestExec(t *testing.T) {
+	newFakeFactory(t)
+	// the commands are not executed by the fake clients, only the resolution of the pods is tested
+	for _, c := range []struct {
+		args []string
+		err  string
+	}{
+		{args: []string{"exec", "storaged-1", "df"}, err: "please specify the pod and the command"},
+		{args: []string{"exec", "storaged-1", "--"}, err: "please specify the pod and the command"},
+		{args: []string{"exec", "storaged-1", "--", "df", "-h"}, err: "pod nebula-storaged-1 is not found, the storaged pods are storaged-0"},
+		{args: []string{"exec", "console-0", "--", "ls"}, err: "unsupported component console"},
+		{args: []string{"exec", "graphd-x", "--", "ls"}, err: "invalid pod graphd-x"},
+		{args: []string{"shell", "metad-2"}, err: "pod nebula-metad-2 is not found, the metad pods are metad-0"},
+	} {
+		_, err := run(t, append([]string{"-c", testCluster}, c.args...)...)
+		if err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Errorf("run %s, expect error %q, but got %v", strings.Join(c.args, " "), c.err, err)
+		}
+	}
+	if _, err := run(t, "shell", "metad", "-c", "missing"); err == nil ||
+		!strings.Contains(err.Error(), "no metad pods of nebula graph cluster missing") {
+		t.Errorf("expect an error of the missing cluster, but got %v", err)
+	}
+}
+
+func TestExecStdin(t *testing.T) {
+	// the master of a pseudo terminal is a terminal as well
+	terminal, err := os.OpenFile("/dev/ptmx", os.O_RDWR, 0)
+	if err != nil {
+		t.Skipf("no pseudo terminal is available: %v", err)
+	}
+	defer terminal.Close()
+	if in := exec.NewStdin(terminal); !in.IsTerminal() {
+		t.Errorf("expect the terminal stdin is detected, the terminal is allocated")
+	}
+	if in := exec.NewStdin(strings.NewReader("ls")); in.IsTerminal() {
+		t.Errorf("expect the piped stdin is not a terminal")
+	}
+}
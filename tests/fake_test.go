@@ -0,0 +1,380 @@
+/*
+ * Copyright (c) 2023 The nebula-contrib Authors.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *     http://www.apache.org/licenses/LICENSE-2.
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vesoft-inc/nebula-operator/apis/apps/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/nebula-contrib/ngctl/cmd"
+	"github.com/nebula-contrib/ngctl/pkg/cluster"
+	"github.com/nebula-contrib/ngctl/pkg/factory"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+const (
+	testCluster   = "nebula"
+	testNamespace = "default"
+	testNode      = "node-1"
+	testNodeIP    = "192.168.1.1"
+)
+
+var (
+	// clusterCreated is the creation time of the nebula graph clusters, it is printed by info
+	clusterCreated = metav1.Date(2023, 9, 10, 8, 0, 0, 0, time.UTC)
+	// podCreated is the creation time of the pods, their ages are printed as 3d
+	podCreated = metav1.NewTime(time.Now().Add(-72 * time.Hour))
+)
+
+func TestMain(m *testing.M) {
+	// the timestamps are printed in the local time zone
+	time.Local = time.UTC
+	os.Exit(m.Run())
+}
+
+// newFakeFactory seeds the fake clients with a nebula graph cluster, its pods, services and volumes, the nodes and
+// nebula operator, and injects them into ngctl, the ngctl config is written to a temporary home directory
+func newFakeFactory(t *testing.T) *factory.Fake {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("KUBECONFIG", "")
+	t.Setenv(cmd.EnvCluster, "")
+	t.Setenv(cmd.EnvNamespace, "")
+
+	objects := []runtime.Object{
+		fakeNode(testNode, testNodeIP),
+		fakeOperator(),
+		fakeService(testCluster+"-graphd-svc", "graphd", corev1.ServiceTypeNodePort,
+			corev1.ServicePort{Name: "thrift", Port: 9669, NodePort: 30669},
+			corev1.ServicePort{Name: "http", Port: 19669, NodePort: 31669}),
+		fakeService(testCluster+"-metad-headless", "metad", corev1.ServiceTypeClusterIP,
+			corev1.ServicePort{Name: "thrift", Port: 9559},
+			corev1.ServicePort{Name: "http", Port: 19559}),
+		fakeService(testCluster+"-storaged-headless", "storaged", corev1.ServiceTypeClusterIP,
+			corev1.ServicePort{Name: "thrift", Port: 9779},
+			corev1.ServicePort{Name: "http", Port: 19779}),
+	}
+	for i, component := range []string{cluster.Graphd, cluster.Metad, cluster.Storaged} {
+		objects = append(objects, fakePod(component, i+1))
+		if component != cluster.Graphd {
+			objects = append(objects, fakeVolume(component))
+		}
+	}
+
+	clusters := []runtime.Object{
+		fakeCluster(t, testCluster, testNamespace),
+		fakeCluster(t, testCluster, "prod"),
+	}
+	resource := v1alpha1.GroupVersion.WithResource("nebulaclusters")
+	f := &factory.Fake{
+		Typed: fake.NewSimpleClientset(objects...),
+		Dynamic: dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+			map[schema.GroupVersionResource]string{resource: "NebulaClusterList"}, clusters...),
+	}
+	cmd.SetFactory(f)
+	t.Cleanup(func() {
+		cmd.SetFactory(nil)
+	})
+	return f
+}
+
+// run runs ngctl with args and returns the output and the logs, the flags are reset in each run
+func run(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	return runWithInput(t, "", args...)
+}
+
+// runWithInput runs ngctl with args and input as stdin
+func runWithInput(t *testing.T, input string, args ...string) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	command := cmd.NewRootCmd()
+	command.SetArgs(args)
+	command.SetIn(strings.NewReader(input))
+	command.SetOut(&out)
+	command.SetErr(&out)
+	command.SilenceUsage = true
+	err := command.Execute()
+	return out.String(), err
+}
+
+// assertGolden compares the output with testdata/name.golden, the golden file is rewritten with -update
+func assertGolden(t *testing.T, name, output string) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(output), 0640); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden file, run the tests with -update to create it: %v", err)
+	}
+	if string(expected) != output {
+		t.Errorf("output differs from %s\nexpected:\n%s\ngot:\n%s", path, expected, output)
+	}
+}
+
+func componentLabels(component string) map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/cluster":    testCluster,
+		"app.kubernetes.io/component":  component,
+		"app.kubernetes.io/managed-by": "nebula-operator",
+		"app.kubernetes.io/name":       "nebula-graph",
+	}
+}
+
+func fakeCluster(t *testing.T, name, namespace string) *unstructured.Unstructured {
+	t.Helper()
+	option := cluster.Presets["dev"]
+	option.Name, option.Namespace = name, namespace
+	nc, err := cluster.Build(&option)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nc.CreationTimestamp = clusterCreated
+	nc.Spec.Graphd.Image = "vesoft/nebula-graphd"
+	nc.Spec.Metad.Image = "vesoft/nebula-metad"
+	nc.Spec.Storaged.Image = "vesoft/nebula-storaged"
+	running := func(replicas int32) v1alpha1.ComponentStatus {
+		return v1alpha1.ComponentStatus{
+			Version:  option.Version,
+			Phase:    v1alpha1.RunningPhase,
+			Workload: v1alpha1.WorkloadStatus{Replicas: replicas, ReadyReplicas: replicas, UpdatedReplicas: replicas},
+		}
+	}
+	nc.Status.Graphd = running(option.GraphdReplicas)
+	nc.Status.Metad = running(option.MetadReplicas)
+	nc.Status.Storaged.ComponentStatus = running(option.StoragedReplicas)
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(nc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &unstructured.Unstructured{Object: content}
+}
+
+func fakePod(component string, index int) *corev1.Pod {
+	name := testCluster + "-" + component
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name + "-0",
+			Namespace:         testNamespace,
+			Labels:            componentLabels(component),
+			CreationTimestamp: podCreated,
+		},
+		Spec: corev1.PodSpec{
+			NodeName: testNode,
+			Containers: []corev1.Container{{
+				Name:  component,
+				Image: "vesoft/nebula-" + component + ":v3.4.0",
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("100m"),
+						corev1.ResourceMemory: resource.MustParse("100Mi"),
+					},
+				},
+			}},
+		},
+		Status: corev1.PodStatus{
+			Phase:  corev1.PodRunning,
+			HostIP: testNodeIP,
+			PodIP:  fmt.Sprintf("10.0.0.%d", index),
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:        component,
+				Ready:       true,
+				ContainerID: "containerd://" + name + "-0",
+				State: corev1.ContainerState{
+					Running: &corev1.ContainerStateRunning{StartedAt: podCreated},
+				},
+			}},
+		},
+	}
+	if component != cluster.Graphd {
+		pod.Spec.Volumes = []corev1.Volume{{
+			Name: "data",
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: volumeClaimName(component)},
+			},
+		}}
+	}
+	return pod
+}
+
+func volumeClaimName(component string) string {
+	return component + "-data-" + testCluster + "-" + component + "-0"
+}
+
+func fakeVolume(component string) *corev1.PersistentVolume {
+	labels := componentLabels(component)
+	return &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "pvc-" + component,
+			Labels: labels,
+		},
+		Spec: corev1.PersistentVolumeSpec{
+			Capacity: corev1.ResourceList{
+				corev1.ResourceStorage: resource.MustParse("5Gi"),
+			},
+			ClaimRef: &corev1.ObjectReference{
+				Kind:      "PersistentVolumeClaim",
+				Namespace: testNamespace,
+				Name:      volumeClaimName(component),
+			},
+			StorageClassName:              "local-path",
+			PersistentVolumeReclaimPolicy: corev1.PersistentVolumeReclaimDelete,
+		},
+		Status: corev1.PersistentVolumeStatus{Phase: corev1.VolumeBound},
+	}
+}
+
+func fakeService(name, component string, typ corev1.ServiceType, ports ...corev1.ServicePort) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: testNamespace,
+			Labels:    componentLabels(component),
+		},
+		Spec: corev1.ServiceSpec{
+			Type:     typ,
+			Ports:    ports,
+			Selector: componentLabels(component),
+		},
+	}
+}
+
+func fakeNode(name, ip string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: corev1.NodeStatus{
+			Addresses: []corev1.NodeAddress{
+				{Type: corev1.NodeHostName, Address: name},
+				{Type: corev1.NodeInternalIP, Address: ip},
+			},
+		},
+	}
+}
+
+func fakeOperator() *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "nebula-operator-controller-manager-deployment",
+			Namespace: "nebula-operator-system",
+			Labels: map[string]string{
+				"app.kubernetes.io/component": "controller-manager",
+				"app.kubernetes.io/instance":  "nebula-operator",
+			},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name:  "controller-manager",
+						Image: "vesoft/nebula-operator:v1.4.2",
+					}},
+				},
+			},
+		},
+	}
+}
+
+// kubeServer is a kubernetes api server which serves the objects by their paths, the paths of the requests are
+// recorded to check which kubernetes cluster the clients are bound to
+type kubeServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []string
+}
+
+func newKubeServer(t *testing.T, objects map[string]runtime.Object) *kubeServer {
+	t.Helper()
+	s := &kubeServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests = append(s.requests, r.URL.Path)
+		s.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		object, ok := objects[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(&metav1.Status{
+				TypeMeta: metav1.TypeMeta{Kind: "Status", APIVersion: "v1"},
+				Status:   metav1.StatusFailure,
+				Reason:   metav1.StatusReasonNotFound,
+				Code:     http.StatusNotFound,
+			})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(object)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Requests returns the paths of the requests served
+func (s *kubeServer) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.requests...)
+}
+
+// writeKubeConfig writes a kubeconfig with a kubernetes context for each server and sets it to KUBECONFIG
+func writeKubeConfig(t *testing.T, current string, servers map[string]*kubeServer) {
+	t.Helper()
+	var b strings.Builder
+	fmt.Fprintf(&b, "apiVersion: v1\nkind: Config\ncurrent-context: %s\nclusters:\n", current)
+	for name, server := range servers {
+		fmt.Fprintf(&b, "- name: %s\n  cluster:\n    server: %s\n", name, server.URL)
+	}
+	b.WriteString("contexts:\n")
+	for name := range servers {
+		fmt.Fprintf(&b, "- name: %s\n  context:\n    cluster: %s\n    user: %s\n", name, name, name)
+	}
+	b.WriteString("users:\n")
+	for name := range servers {
+		fmt.Fprintf(&b, "- name: %s\n  user:\n    token: %s\n", name, name)
+	}
+	path := filepath.Join(t.TempDir(), "kubeconfig")
+	if err := os.WriteFile(path, []byte(b.String()), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("KUBECONFIG", path)
+}
@@ -0,0 +1,99 @@
+/*
+ * Copyright (c) 2023 The nebula-contrib Authors.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *     http://www.apache.org/licenses/LICENSE-2.
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nebula-contrib/ngctl/pkg/glog"
+)
+
+func TestGlogParse(t *testing.T) {
+	year := time.Now().UTC().Year()
+	if time.Now().UTC().Before(time.Date(year, 9, 6, 7, 15, 1, 0, time.UTC)) {
+		// the line of September is more than a day ahead, it is logged in the previous year
+		year--
+	}
+	for _, c := range []struct {
+		line   string
+		ok     bool
+		record glog.Record
+	}{
+		{
+			line: "E20230907 07:15:01.123456 1234 File.cpp:42] msg",
+			ok:   true,
+			record: glog.Record{Severity: glog.Error, Time: time.Date(2023, 9, 7, 7, 15, 1, 123456000, time.UTC),
+				Thread: 1234, File: "File.cpp", Line: 42, Message: "msg"},
+		},
+		{
+			line: "I0907 07:15:01.000001     1 MetaClient.cpp:2642] Send heartbeat to \"nebula-metad-0\":9559\n",
+			ok:   true,
+			record: glog.Record{Severity: glog.Info, Time: time.Date(year, 9, 7, 7, 15, 1, 1000, time.UTC),
+				Thread: 1, File: "MetaClient.cpp", Line: 2642, Message: "Send heartbeat to \"nebula-metad-0\":9559"},
+		},
+		{
+			line:   "W20230907 07:15:01.123456 7 StorageServer.cpp:9]",
+			ok:     true,
+			record: glog.Record{Severity: glog.Warning, Time: time.Date(2023, 9, 7, 7, 15, 1, 123456000, time.UTC), Thread: 7, File: "StorageServer.cpp", Line: 9},
+		},
+		{line: "    @     0x55d6b4c3 folly::LogMessage::~LogMessage()"},
+		{line: "X20230907 07:15:01.123456 1234 File.cpp:42] unknown severity"},
+		{line: "E20231307 07:15:01.123456 1234 File.cpp:42] invalid month"},
+		{line: ""},
+	} {
+		record, ok := glog.Parse(c.line)
+		if ok != c.ok || record != c.record {
+			t.Errorf("parse %q, expect %+v %v, but got %+v %v", c.line, c.record, c.ok, record, ok)
+		}
+	}
+}
+
+func TestGlogParseYear(t *testing.T) {
+	newYear := time.Date(2024, 1, 1, 0, 5, 0, 0, time.UTC)
+	for _, c := range []struct {
+		line     string
+		now      time.Time
+		expected time.Time
+	}{
+		// the line of the new year eve read after midnight is logged in the previous year
+		{line: "I1231 23:59:59.000000 1 File.cpp:1] msg", now: newYear, expected: time.Date(2023, 12, 31, 23, 59, 59, 0, time.UTC)},
+		{line: "I0101 00:04:00.000000 1 File.cpp:1] msg", now: newYear, expected: time.Date(2024, 1, 1, 0, 4, 0, 0, time.UTC)},
+		// the clock of the pod is allowed to be ahead of the local one within a day
+		{line: "I0101 12:00:00.000000 1 File.cpp:1] msg", now: newYear, expected: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)},
+		{line: "I0102 12:00:00.000000 1 File.cpp:1] msg", now: newYear, expected: time.Date(2023, 1, 2, 12, 0, 0, 0, time.UTC)},
+		// the 29th of February is only valid in a leap year
+		{line: "I0229 08:00:00.000000 1 File.cpp:1] msg", now: time.Date(2029, 1, 10, 0, 0, 0, 0, time.UTC), expected: time.Date(2028, 2, 29, 8, 0, 0, 0, time.UTC)},
+		{line: "I0229 08:00:00.000000 1 File.cpp:1] msg", now: time.Date(2028, 3, 1, 0, 0, 0, 0, time.UTC), expected: time.Date(2028, 2, 29, 8, 0, 0, 0, time.UTC)},
+		{line: "I0229 08:00:00.000000 1 File.cpp:1] msg", now: time.Date(2028, 1, 10, 0, 0, 0, 0, time.UTC), expected: time.Date(2028, 2, 29, 8, 0, 0, 0, time.UTC)},
+		// the year of the line is kept even if it is in the future
+		{line: "I20241231 23:59:59.000000 1 File.cpp:1] msg", now: newYear, expected: time.Date(2024, 12, 31, 23, 59, 59, 0, time.UTC)},
+	} {
+		record, ok := glog.ParseAt(c.line, c.now)
+		if !ok || !record.Time.Equal(c.expected) {
+			t.Errorf("parse %q at %s, expect %s, but got %s %v", c.line, c.now, c.expected, record.Time, ok)
+		}
+	}
+}
+
+func TestGlogSeverity(t *testing.T) {
+	for s, expected := range map[string]glog.Severity{"E": glog.Error, "w": glog.Warning, "info": glog.Info, "FATAL": glog.Fatal} {
+		if severity, err := glog.ParseSeverity(s); err != nil || severity != expected {
+			t.Errorf("parse severity %s, expect %s, but got %s %v", s, expected, severity, err)
+		}
+	}
+	if _, err := glog.ParseSeverity("D"); err == nil {
+		t.Error("expect an error of an unknown severity")
+	}
+}
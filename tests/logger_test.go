@@ -0,0 +1,126 @@
+/*
+ * Copyright (c) 2023 The nebula-contrib Authors.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *     http://www.apache.org/licenses/LICENSE-2.
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"k8s.io/klog/v2"
+
+	"github.com/nebula-contrib/ngctl/cmd"
+	"github.com/nebula-contrib/ngctl/pkg/logger"
+)
+
+// runSplit runs ngctl with args and returns stdout and stderr separately
+func runSplit(t *testing.T, args ...string) (string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	command := cmd.NewRootCmd()
+	command.SetArgs(args)
+	command.SetOut(&stdout)
+	command.SetErr(&stderr)
+	if err := command.Execute(); err != nil {
+		t.Fatalf("run %s error: %v", strings.Join(args, " "), err)
+	}
+	return stdout.String(), stderr.String()
+}
+
+func TestLogging(t *testing.T) {
+	newFakeFactory(t)
+	t.Run("results to stdout", func(t *testing.T) {
+		stdout, stderr := runSplit(t, "info", "-c", testCluster)
+		if !strings.Contains(stdout, "Overview:") || stderr != "" {
+			t.Errorf("expect the tables in stdout only, but got stdout %q and stderr %q", stdout, stderr)
+		}
+	})
+	t.Run("quiet", func(t *testing.T) {
+		stdout, stderr := runSplit(t, "list", "-n", "empty", "--quiet")
+		if stdout != "" || stderr != "" {
+			t.Errorf("expect no output, but got stdout %q and stderr %q", stdout, stderr)
+		}
+	})
+	t.Run("json events", func(t *testing.T) {
+		runSplit(t, "studio", "install", "--name", "studio-json")
+		_, stderr := runSplit(t, "studio", "uninstall", "--name", "studio-json", "--log-format", "json")
+		lines := strings.Split(strings.TrimSpace(stderr), "\n")
+		if len(lines) != 2 {
+			t.Fatalf("expect 2 events, but got %q", stderr)
+		}
+		var event map[string]string
+		if err := json.Unmarshal([]byte(lines[0]), &event); err != nil {
+			t.Fatalf("invalid json log %q: %v", lines[0], err)
+		}
+		for key, value := range map[string]string{
+			"level":     "info",
+			"msg":       "resource is removed",
+			"kind":      "Deployment",
+			"name":      "studio-json",
+			"namespace": testNamespace,
+		} {
+			if event[key] != value {
+				t.Errorf("expect %s is %s, but got %s", key, value, event[key])
+			}
+		}
+	})
+	t.Run("json klog", func(t *testing.T) {
+		var stderr bytes.Buffer
+		if err := logger.Configure(&stderr, 6, false, logger.FormatJSON); err != nil {
+			t.Fatal(err)
+		}
+		defer func() {
+			_ = logger.Configure(os.Stderr, 0, false, logger.FormatText)
+		}()
+		// the requests are logged by the kubernetes clients with klog
+		klog.V(6).Infof("GET https://fake/api/v1/namespaces/default/pods 200 OK in 3 milliseconds")
+		klog.V(7).Infof("dropped by the verbosity")
+		klog.ErrorS(errors.New("connection refused"), "request failed", "verb", "GET")
+		lines := strings.Split(strings.TrimSpace(stderr.String()), "\n")
+		if len(lines) != 2 {
+			t.Fatalf("expect 2 logs, but got %q", stderr.String())
+		}
+		for i, expected := range []map[string]string{
+			{"level": "info", "msg": "GET https://fake/api/v1/namespaces/default/pods 200 OK in 3 milliseconds"},
+			{"level": "error", "msg": "request failed", "verb": "GET", "err": "connection refused"},
+		} {
+			var log map[string]string
+			if err := json.Unmarshal([]byte(lines[i]), &log); err != nil {
+				t.Fatalf("invalid json log %q: %v", lines[i], err)
+			}
+			for key, value := range expected {
+				if log[key] != value {
+					t.Errorf("expect %s is %s, but got %s in %q", key, value, log[key], lines[i])
+				}
+			}
+		}
+	})
+	t.Run("invalid options", func(t *testing.T) {
+		for _, args := range [][]string{
+			{"list", "-v", "10"},
+			{"list", "--log-format", "xml"},
+		} {
+			command := cmd.NewRootCmd()
+			command.SetArgs(args)
+			command.SetOut(&bytes.Buffer{})
+			command.SetErr(&bytes.Buffer{})
+			if err := command.Execute(); err == nil {
+				t.Errorf("expect an error of %s", strings.Join(args, " "))
+			}
+		}
+	})
+}
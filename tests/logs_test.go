@@ -0,0 +1,195 @@
+/*
+ * Copyright (c) 2023 The nebula-contrib Authors.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *     http://www.apache.org/licenses/LICENSE-2.
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package tests
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/nebula-contrib/ngctl/pkg/cluster"
+)
+
+// sortedLines returns the lines of the output in order since the pods are streamed concurrently
+func sortedLines(output string) []string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	sort.Strings(lines)
+	return lines
+}
+
+// logOptions returns the log options of the requests of the pod logs
+func logOptions(client *fake.Clientset) []*corev1.PodLogOptions {
+	var options []*corev1.PodLogOptions
+	for _, action := range client.Actions() {
+		if action.GetSubresource() != "log" {
+			continue
+		}
+		if generic, ok := action.(k8stesting.GenericAction); ok {
+			options = append(options, generic.GetValue().(*corev1.PodLogOptions))
+		}
+	}
+	return options
+}
+
+func TestLogs(t *testing.T) {
+	t.Run("all", func(t *testing.T) {
+		newFakeFactory(t)
+		out, err := run(t, "logs", "all", "-c", testCluster, "--color", "never")
+		if err != nil {
+			t.Fatalf("run logs error: %v", err)
+		}
+		expected := []string{
+			"[nebula-graphd-0] fake logs",
+			"[nebula-metad-0] fake logs",
+			"[nebula-storaged-0] fake logs",
+		}
+		if lines := sortedLines(out); strings.Join(lines, "\n") != strings.Join(expected, "\n") {
+			t.Errorf("expect %q, but got %q", expected, lines)
+		}
+	})
+	t.Run("options", func(t *testing.T) {
+		f := newFakeFactory(t)
+		out, err := run(t, "logs", "graphd", "-c", testCluster, "--tail", "10", "--since", "5m", "--previous",
+			"--grep", "^E", "--color", "always")
+		if err != nil {
+			t.Fatalf("run logs error: %v", err)
+		}
+		if out != "" {
+			t.Errorf("expect the lines are filtered by --grep, but got %q", out)
+		}
+		options := logOptions(f.Typed.(*fake.Clientset))
+		if len(options) != 1 {
+			t.Fatalf("expect the logs of 1 graphd pod, but got %d", len(options))
+		}
+		if o := options[0]; o.Container != cluster.Graphd || *o.TailLines != 10 || *o.SinceSeconds != 300 || !o.Previous || o.Follow {
+			t.Errorf("unexpected log options %+v", o)
+		}
+	})
+	t.Run("follow", func(t *testing.T) {
+		f := newFakeFactory(t)
+		client := f.Typed.(*fake.Clientset)
+		watching := make(chan struct{})
+		client.PrependWatchReactor("pods", func(action k8stesting.Action) (bool, watch.Interface, error) {
+			close(watching)
+			return false, nil, nil
+		})
+		go func() {
+			<-watching
+			// the watcher is registered after the reactors return
+			time.Sleep(100 * time.Millisecond)
+			pod := fakePod(cluster.Graphd, 4)
+			pod.Name, pod.UID = testCluster+"-graphd-1", "graphd-1"
+			_, _ = client.CoreV1().Pods(testNamespace).Create(context.Background(), pod, metav1.CreateOptions{})
+		}()
+		out, err := run(t, "logs", "graphd", "-c", testCluster, "-f", "--tail", "1", "--timeout", "1s")
+		if err != nil {
+			t.Fatalf("run logs error: %v", err)
+		}
+		expected := []string{
+			"[nebula-graphd-0] fake logs",
+			"[nebula-graphd-1] fake logs",
+		}
+		if lines := sortedLines(out); strings.Join(lines, "\n") != strings.Join(expected, "\n") {
+			t.Errorf("expect %q, but got %q", expected, lines)
+		}
+		options := logOptions(client)
+		if len(options) != 2 || options[1].TailLines != nil {
+			t.Errorf("expect the new pod is streamed from the beginning, but got %+v", options)
+		}
+	})
+	t.Run("expired watch", func(t *testing.T) {
+		f := newFakeFactory(t)
+		client := f.Typed.(*fake.Clientset)
+		watches := 0
+		client.PrependWatchReactor("pods", func(action k8stesting.Action) (bool, watch.Interface, error) {
+			if watches++; watches > 1 {
+				return false, nil, nil
+			}
+			// the pod is created while the resource version is expired, it is only found by listing again
+			pod := fakePod(cluster.Graphd, 4)
+			pod.Name, pod.UID = testCluster+"-graphd-1", "graphd-1"
+			if err := client.Tracker().Add(pod); err != nil {
+				t.Error(err)
+			}
+			w := watch.NewFakeWithChanSize(1, false)
+			w.Error(&apierrors.NewResourceExpired("too old resource version").ErrStatus)
+			return true, w, nil
+		})
+		out, err := run(t, "logs", "graphd", "-c", testCluster, "-f", "--since", "100ms", "--timeout", "2s")
+		if err != nil {
+			t.Fatalf("run logs error: %v", err)
+		}
+		expected := []string{
+			"[nebula-graphd-0] fake logs",
+			"[nebula-graphd-1] fake logs",
+		}
+		if lines := sortedLines(out); strings.Join(lines, "\n") != strings.Join(expected, "\n") {
+			t.Errorf("expect %q, but got %q", expected, lines)
+		}
+		lists := 0
+		for _, action := range client.Actions() {
+			if action.GetVerb() == "list" && action.GetResource().Resource == "pods" {
+				lists++
+			}
+		}
+		if lists != 2 || watches != 2 {
+			t.Errorf("expect the pods are listed and watched again, but got %d lists and %d watches", lists, watches)
+		}
+		// the pods are streamed concurrently, only the initial one has --since
+		rounded := false
+		for _, o := range logOptions(client) {
+			rounded = rounded || (o.SinceSeconds != nil && *o.SinceSeconds == 1)
+		}
+		if !rounded {
+			t.Errorf("expect --since is rounded up to 1s, but got %+v", logOptions(client))
+		}
+	})
+	t.Run("json", func(t *testing.T) {
+		newFakeFactory(t)
+		out, err := run(t, "logs", "graphd", "-c", testCluster, "-o", "json")
+		if err != nil {
+			t.Fatalf("run logs error: %v", err)
+		}
+		// the lines which are not in the glog format only have the message
+		if expected := `{"pod":"nebula-graphd-0","message":"fake logs"}` + "\n"; out != expected {
+			t.Errorf("expect %q, but got %q", expected, out)
+		}
+		if out, err = run(t, "logs", "graphd", "-c", testCluster, "--level", "E,W"); err != nil || out != "" {
+			t.Errorf("expect the lines without severity are filtered by --level, but got %q %v", out, err)
+		}
+		for _, args := range [][]string{{"--level", "D"}, {"-o", "yaml"}} {
+			if _, err = run(t, append([]string{"logs", "graphd", "-c", testCluster}, args...)...); err == nil {
+				t.Errorf("expect an error of %v", args)
+			}
+		}
+	})
+	t.Run("no pods", func(t *testing.T) {
+		newFakeFactory(t)
+		if _, err := run(t, "logs", "graphd", "-c", "missing"); err == nil {
+			t.Error("expect an error if no pods are found")
+		}
+		if _, err := run(t, "logs", "nebula", "-c", testCluster); err == nil {
+			t.Error("expect an error of an unsupported component")
+		}
+	})
+}
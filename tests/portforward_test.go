@@ -0,0 +1,148 @@
+/*
+ * Copyright (c) 2023 The nebula-contrib Authors.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *     http://www.apache.org/licenses/LICENSE-2.
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/nebula-contrib/ngctl/cmd"
+	"github.com/nebula-contrib/ngctl/pkg/cluster"
+	"github.com/nebula-contrib/ngctl/pkg/config"
+	"github.com/nebula-contrib/ngctl/pkg/portforward"
+)
+
+func TestPortForward(t *testing.T) {
+	newFakeFactory(t)
+	// the ports are not forwarded by the fake clients, only the resolution of the targets is tested
+	for _, c := range []struct {
+		args []string
+		err  string
+	}{
+		{args: []string{"port-forward", "metad-0", "grpc"}, err: "port grpc is not found, the ports of nebula-metad-headless are thrift, http"},
+		{args: []string{"port-forward", "storaged-3"}, err: "pod nebula-storaged-3 is not found, the storaged pods are storaged-0"},
+		{args: []string{"port-forward", "console"}, err: "unsupported component console"},
+		{args: []string{"port-forward", "studio"}, err: "nebula graph studio studio is not found in namespace default"},
+	} {
+		_, err := run(t, append([]string{"-c", testCluster}, c.args...)...)
+		if err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Errorf("run %s, expect error %q, but got %v", strings.Join(c.args, " "), c.err, err)
+		}
+	}
+}
+
+func TestPortForwardContext(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(cmd.EnvCluster, "")
+	t.Setenv(cmd.EnvNamespace, "")
+	service := fakeService(testCluster+"-metad-headless", cluster.Metad, corev1.ServiceTypeClusterIP,
+		corev1.ServicePort{Name: "thrift", Port: 9559})
+	service.TypeMeta = metav1.TypeMeta{Kind: "Service", APIVersion: "v1"}
+	pod := fakePod(cluster.Metad, 1)
+	pod.TypeMeta = metav1.TypeMeta{Kind: "Pod", APIVersion: "v1"}
+	pod.Spec.Containers[0].Ports = []corev1.ContainerPort{{Name: "thrift", ContainerPort: 9559}}
+	prod := newKubeServer(t, map[string]runtime.Object{
+		"/api/v1/namespaces/default/services": &corev1.ServiceList{
+			TypeMeta: metav1.TypeMeta{Kind: "ServiceList", APIVersion: "v1"},
+			Items:    []corev1.Service{*service},
+		},
+		"/api/v1/namespaces/default/pods/nebula-metad-0": pod,
+	})
+	local := newKubeServer(t, nil)
+	writeKubeConfig(t, "local", map[string]*kubeServer{"local": local, "prod": prod})
+	// the context in use belongs to the kubernetes context prod, which is not the current one
+	err := config.UseContext(config.Context{Name: "prod", Cluster: testCluster, Namespace: testNamespace, KubeContext: "prod"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the ports are not forwarded by the fake api server, only the cluster of the requests is checked
+	if _, err = run(t, "port-forward", "metad-0"); err == nil {
+		t.Errorf("expect the port forwarding is rejected by the fake api server")
+	}
+	if requests := local.Requests(); len(requests) != 0 {
+		t.Errorf("expect no requests to the current kubernetes context, but got %v", requests)
+	}
+	requests := strings.Join(prod.Requests(), " ")
+	for _, path := range []string{
+		"/api/v1/namespaces/default/services",
+		"/api/v1/namespaces/default/pods/nebula-metad-0",
+		"/api/v1/namespaces/default/pods/nebula-metad-0/portforward",
+	} {
+		if !strings.Contains(requests, path) {
+			t.Errorf("expect request %s to the kubernetes context prod, but got %s", path, requests)
+		}
+	}
+}
+
+func TestPortForwardResolve(t *testing.T) {
+	t.Run("local port", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer listener.Close()
+		busy := int32(listener.Addr().(*net.TCPAddr).Port)
+		if spec := portforward.Spec("127.0.0.1", portforward.Port{Remote: busy}); spec != fmt.Sprintf(":%d", busy) {
+			t.Errorf("expect a free port is bound by the forwarder for busy port %d, but got %s", busy, spec)
+		}
+		if spec := portforward.Spec("127.0.0.1", portforward.Port{Remote: busy, Local: 19000}); spec != fmt.Sprintf("19000:%d", busy) {
+			t.Errorf("expect the preferred port, but got %s", spec)
+		}
+		_ = listener.Close()
+		if spec := portforward.Spec("127.0.0.1", portforward.Port{Remote: busy}); spec != fmt.Sprintf("%d:%d", busy, busy) {
+			t.Errorf("expect the same port as the free remote port, but got %s", spec)
+		}
+	})
+	t.Run("service pod", func(t *testing.T) {
+		pending := fakePod(cluster.Graphd, 2)
+		pending.Name, pending.Status.Phase = "nebula-graphd-0", corev1.PodPending
+		running := fakePod(cluster.Graphd, 3)
+		running.Name = "nebula-graphd-1"
+		client := fake.NewSimpleClientset(pending, running)
+		service := fakeService("nebula-graphd-svc", cluster.Graphd, corev1.ServiceTypeClusterIP)
+		pod, err := portforward.ServicePod(context.Background(), client, service)
+		if err != nil || pod.Name != "nebula-graphd-1" {
+			t.Errorf("expect the running pod nebula-graphd-1, but got %v %v", pod, err)
+		}
+	})
+	t.Run("target port", func(t *testing.T) {
+		pod := fakePod(cluster.Graphd, 1)
+		pod.Spec.Containers[0].Ports = []corev1.ContainerPort{{Name: "thrift", ContainerPort: 9669}}
+		for _, c := range []struct {
+			port     corev1.ServicePort
+			expected int32
+		}{
+			{port: corev1.ServicePort{Port: 9669}, expected: 9669},
+			{port: corev1.ServicePort{Port: 80, TargetPort: intstr.FromInt(9669)}, expected: 9669},
+			{port: corev1.ServicePort{Port: 80, TargetPort: intstr.FromString("thrift")}, expected: 9669},
+		} {
+			if port, err := portforward.TargetPort(pod, c.port); err != nil || port != c.expected {
+				t.Errorf("expect target port %d of %+v, but got %d %v", c.expected, c.port, port, err)
+			}
+		}
+		if _, err := portforward.TargetPort(pod, corev1.ServicePort{TargetPort: intstr.FromString("http")}); err == nil {
+			t.Error("expect an error of the missing named port")
+		}
+	})
+}
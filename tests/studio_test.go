@@ -15,135 +15,261 @@ package tests
 
 import (
 	"context"
-	"fmt"
-	"path/filepath"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
 	"testing"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/util/homedir"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/yaml"
 
 	"github.com/nebula-contrib/ngctl/cmd"
-	"github.com/nebula-contrib/ngctl/pkg/util"
 )
 
 func TestStudio(t *testing.T) {
-	var command = cmd.RootCmd
-	t.Run("studio install", func(t *testing.T) {
-		// consider test in local environment with kubeconfig file in default path ~/.kube/config
-		var namespace = "default"
-		var name = "studio-test"
-		set, err := util.NewClientSet(filepath.Join(homedir.HomeDir(), ".kube", "config"))
-		if err != nil {
-			t.Errorf("create client set error: %v", err)
-		}
-		ctx := context.Background()
+	f := newFakeFactory(t)
+	var namespace = "default"
+	var name = "studio-test"
+	ctx := context.Background()
 
+	t.Run("studio install", func(t *testing.T) {
 		// run studio install command
-		command.SetArgs([]string{"studio", "install", "--name", name})
-		err = command.Execute()
+		output, err := run(t, "studio", "install", "--name", name)
 		if err != nil {
-			t.Errorf("run studio command error: %v", err)
+			t.Fatalf("run studio command error: %v", err)
 		}
 		// check whether the deployment is created
-		deploy, err := set.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		deploy, err := f.Typed.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
 		if err != nil {
-			t.Errorf("get deployment error: %v", err)
-		}
-		if deploy.Name != name {
-			t.Errorf("expect deployment name is %s, but got %s", name, deploy.Name)
+			t.Fatalf("get deployment error: %v", err)
 		}
 		//	 check whether the service is created
-		svc, err := set.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
+		svc, err := f.Typed.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
 		if err != nil {
-			t.Errorf("get service error: %v", err)
+			t.Fatalf("get service error: %v", err)
 		}
-		if svc.Name != name {
-			t.Errorf("expect service name is %s, but got %s", name, svc.Name)
+		for _, object := range []interface{}{deploy, svc} {
+			content, err := yaml.Marshal(object)
+			if err != nil {
+				t.Fatal(err)
+			}
+			output += "---\n" + string(content)
 		}
+		assertGolden(t, "studio-install", output)
 	})
 	t.Run("studio uninstall", func(t *testing.T) {
-		var name = "studio-test"
-
 		//   run studio uninstall command
-		command.SetArgs([]string{"studio", "uninstall", "--name", name})
-		err := command.Execute()
+		output, err := run(t, "studio", "uninstall", "--name", name)
 		if err != nil {
-			t.Errorf("run studio command error: %v", err)
+			t.Fatalf("run studio command error: %v", err)
 		}
+		if _, err = f.Typed.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{}); err == nil {
+			t.Errorf("expect deployment %s is removed", name)
+		}
+		assertGolden(t, "studio-uninstall", output)
 	})
 }
 
 func TestVersion(t *testing.T) {
-	var command = cmd.RootCmd
-	t.Run("version", func(t *testing.T) {
-		// run version command
-		command.SetArgs([]string{"version"})
-		err := command.Execute()
-		if err != nil {
-			t.Errorf("run version command error: %v", err)
-		}
-	})
+	newFakeFactory(t)
+	// run version command
+	output, err := run(t, "version")
+	if err != nil {
+		t.Fatalf("run version command error: %v", err)
+	}
+	assertGolden(t, "version", output)
 }
 
 func TestUse(t *testing.T) {
-	var command = cmd.RootCmd
-	t.Run("use", func(t *testing.T) {
-		// run use command
-		command.SetArgs([]string{"use", "nebula"})
-		err := command.Execute()
-		if err != nil {
-			t.Errorf("run use command error: %v", err)
+	newFakeFactory(t)
+	// run use command
+	if _, err := run(t, "use", testCluster, "-n", "prod", "--context-name", "prod"); err != nil {
+		t.Fatalf("run use command error: %v", err)
+	}
+	output, err := run(t, "context", "current")
+	if err != nil {
+		t.Fatalf("run context command error: %v", err)
+	}
+	if output != "prod\n" {
+		t.Errorf("expect the current context is prod, but got %q", output)
+	}
+	if _, err = run(t, "use", "unknown"); err == nil {
+		t.Errorf("expect an error for the unknown cluster")
+	}
+}
+
+func TestUseSameName(t *testing.T) {
+	newFakeFactory(t)
+	// the clusters of the same name in different namespaces get their own contexts
+	for _, namespace := range []string{"default", "prod", "default"} {
+		if _, err := run(t, "use", testCluster, "-n", namespace); err != nil {
+			t.Fatalf("run use command error: %v", err)
 		}
-	})
+	}
+	output, err := run(t, "context", "list")
+	if err != nil {
+		t.Fatalf("run context command error: %v", err)
+	}
+	assertGolden(t, "use-same-name", output)
 }
 
 func TestInfo(t *testing.T) {
-	var command = cmd.RootCmd
-	t.Run("info", func(t *testing.T) {
-		// run info command
-		command.SetArgs([]string{"info"})
-		err := command.Execute()
+	newFakeFactory(t)
+	cases := map[string][]string{
+		"info":      {"info", "-c", testCluster},
+		"info-json": {"info", "-c", testCluster, "-o", "json"},
+	}
+	for name, args := range cases {
+		t.Run(name, func(t *testing.T) {
+			// run info command
+			output, err := run(t, args...)
+			if err != nil {
+				t.Fatalf("run info command error: %v", err)
+			}
+			assertGolden(t, name, output)
+		})
+	}
+	t.Run("info of the context", func(t *testing.T) {
+		if _, err := run(t, "use", testCluster); err != nil {
+			t.Fatalf("run use command error: %v", err)
+		}
+		output, err := run(t, "info")
 		if err != nil {
-			t.Errorf("run info command error: %v", err)
+			t.Fatalf("run info command error: %v", err)
 		}
+		assertGolden(t, "info", output)
 	})
 }
 
 func TestList(t *testing.T) {
-	var command = cmd.RootCmd
-	t.Run("list", func(t *testing.T) {
-		// run list command
-		command.SetArgs([]string{"list"})
-		err := command.Execute()
-		if err != nil {
-			t.Errorf("run list command error: %v", err)
-		}
-	})
+	newFakeFactory(t)
+	cases := map[string][]string{
+		"list":      {"list"},
+		"list-all":  {"list", "-A"},
+		"list-name": {"list", "-A", "-o", "name"},
+		"list-none": {"list", "-n", "empty"},
+	}
+	for name, args := range cases {
+		t.Run(name, func(t *testing.T) {
+			// run list command
+			output, err := run(t, args...)
+			if err != nil {
+				t.Fatalf("run list command error: %v", err)
+			}
+			assertGolden(t, name, output)
+		})
+	}
 }
 
 func TestGet(t *testing.T) {
-	var command = cmd.RootCmd
+	newFakeFactory(t)
 	args := []string{"metad", "storaged", "graphd", "volume"}
 	for _, v := range args {
-		t.Run(fmt.Sprintf("get %s", v), func(t *testing.T) {
-			command.SetArgs([]string{"get", v})
-			err := command.Execute()
+		t.Run("get "+v, func(t *testing.T) {
+			output, err := run(t, "get", v, "-c", testCluster, "-o", "wide")
 			if err != nil {
-				t.Errorf("run get %s command error: %v", v, err)
+				t.Fatalf("run get %s command error: %v", v, err)
 			}
+			assertGolden(t, "get-"+v, output)
 		})
 	}
+	t.Run("get all namespaces", func(t *testing.T) {
+		output, err := run(t, "get", "metad", "-c", testCluster, "-A", "-o", "name")
+		if err != nil {
+			t.Fatalf("run get command error: %v", err)
+		}
+		assertGolden(t, "get-all", output)
+	})
+	t.Run("get without cluster", func(t *testing.T) {
+		_, err := run(t, "get", "graphd")
+		if err == nil || !strings.Contains(err.Error(), "default/nebula, prod/nebula") {
+			t.Errorf("expect the available clusters in the error, but got %v", err)
+		}
+	})
+}
 
+func TestTimeout(t *testing.T) {
+	newFakeFactory(t)
+	// the confirmation prompt is never answered
+	in, w := io.Pipe()
+	defer w.Close()
+	command := cmd.NewRootCmd()
+	command.SetArgs([]string{"delete", testCluster, "--timeout", "100ms"})
+	command.SetIn(in)
+	command.SetOut(io.Discard)
+	command.SetErr(io.Discard)
+	err := command.Execute()
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expect the deadline is exceeded, but got %v", err)
+	}
 }
 
+// envE2E enables the tests against the kubernetes cluster of the kubeconfig, they are skipped by default so that
+// go test never touches a real cluster by accident
+const envE2E = "NGCTL_E2E"
+
+// TestConsole runs nebula console in the nebula graph cluster in use, it runs only if NGCTL_E2E=true
 func TestConsole(t *testing.T) {
-	var command = cmd.RootCmd
-	t.Run("console", func(t *testing.T) {
-		// run console command
-		command.SetArgs([]string{"console", "-u", "root", "-p", "nebula"})
+	if e2e, _ := strconv.ParseBool(os.Getenv(envE2E)); !e2e {
+		t.Skipf("%s is not set, the tests against a kubernetes cluster are skipped", envE2E)
+	}
+	var command = cmd.NewRootCmd()
+	// run console command
+	command.SetArgs([]string{"console", "-u", "root", "-p", "nebula"})
+	err := command.Execute()
+	if err != nil {
+		t.Errorf("run console command error: %v", err)
+	}
+}
+
+func TestConsoleFlags(t *testing.T) {
+	command := cmd.NewRootCmd()
+	console, _, err := command.Find([]string{"console"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the account of nebula graph and the kubeconfig user can be set together
+	if err = console.ParseFlags([]string{"-u", "graph", "--user", "kube-admin"}); err != nil {
+		t.Fatalf("parse console flags error: %v", err)
+	}
+	if user, _ := console.Flags().GetString("graph-user"); user != "graph" {
+		t.Errorf("expect the nebula graph account graph, but got %s", user)
+	}
+	if user, _ := console.Flags().GetString("user"); user != "kube-admin" {
+		t.Errorf("expect the kubeconfig user kube-admin, but got %s", user)
+	}
+}
+
+func TestConsoleCleanup(t *testing.T) {
+	f := newFakeFactory(t)
+	client := f.Typed.(*fake.Clientset)
+	client.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("pods is forbidden")
+	})
+	if _, err := run(t, "-c", testCluster, "console"); err == nil || !strings.Contains(err.Error(), "pods is forbidden") {
+		t.Fatalf("expect the pod is failed to be created, but got %v", err)
+	}
+	// the config map created before the pod is removed
+	_, err := client.CoreV1().ConfigMaps(testNamespace).Get(context.Background(), "nebula-console", metav1.GetOptions{})
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("expect the config map of console is removed, but got %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	var command = cmd.NewRootCmd()
+	t.Run("validate", func(t *testing.T) {
+		// validate the example manifest, no kubernetes cluster is needed
+		command.SetArgs([]string{"validate", "-f", "../example.yaml"})
 		err := command.Execute()
 		if err != nil {
-			t.Errorf("run console command error: %v", err)
+			t.Errorf("run validate command error: %v", err)
 		}
 	})
 }
@@ -0,0 +1,44 @@
+/*
+ * Copyright (c) 2023 The nebula-contrib Authors.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *     http://www.apache.org/licenses/LICENSE-2.
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nebula-contrib/ngctl/pkg/cluster"
+)
+
+func TestTemplateValidate(t *testing.T) {
+	newFakeFactory(t)
+	// the manifests of the built-in profiles are complete, they pass validate as they are
+	for _, profile := range cluster.PresetNames() {
+		manifest, err := run(t, "template", "--profile", profile)
+		if err != nil {
+			t.Fatalf("run template --profile %s error: %v", profile, err)
+		}
+		if !strings.Contains(manifest, "storageClassName: standard") {
+			t.Errorf("expect the default storage class in profile %s, but got\n%s", profile, manifest)
+		}
+		out, err := runWithInput(t, manifest, "validate", "-f", "-")
+		if err != nil || !strings.Contains(out, "0 errors, 0 warnings") {
+			t.Errorf("expect profile %s passes validate, but got %v\n%s", profile, err, out)
+		}
+	}
+
+	manifest, err := run(t, "template", "--storage-class", "fast")
+	if err != nil || strings.Contains(manifest, "storageClassName: standard") || !strings.Contains(manifest, "storageClassName: fast") {
+		t.Errorf("expect --storage-class overrides the default, but got %v\n%s", err, manifest)
+	}
+}
@@ -0,0 +1,184 @@
+/*
+ * Copyright (c) 2023 The nebula-contrib Authors.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *     http://www.apache.org/licenses/LICENSE-2.
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/nebula-contrib/ngctl/pkg/util"
+)
+
+const testLabel = "ngctl/test"
+
+func testMeta(name string, labels map[string]string) metav1.ObjectMeta {
+	meta := metav1.ObjectMeta{Name: name, Namespace: testNamespace, Labels: map[string]string{testLabel: name}}
+	for k, v := range labels {
+		meta.Labels[k] = v
+	}
+	return meta
+}
+
+// lifecycle creates, updates and removes elem, and checks its status after each step
+func lifecycle[T util.Object](t *testing.T, si util.ResourceInterface[T], elem T) {
+	t.Helper()
+	ctx := context.Background()
+	name := elem.GetName()
+	assertStatus := func(expected util.Status) {
+		t.Helper()
+		status, err := util.Check[T](ctx, si, name, testLabel)
+		if err != nil || status != expected {
+			t.Fatalf("expect status %d of %s, but got %d, %v", expected, name, status, err)
+		}
+	}
+	assertStatus(util.StatusNotFound)
+	if err := util.CreateOrUpdate[T](ctx, si, elem, testLabel, false); err != nil {
+		t.Fatalf("create %s error: %v", name, err)
+	}
+	assertStatus(util.StatusAlready)
+	if err := util.CreateOrUpdate[T](ctx, si, elem, testLabel, false); err != nil {
+		t.Fatalf("update %s error: %v", name, err)
+	}
+	if err := util.RemoveResource[T](ctx, si, name, testLabel); err != nil {
+		t.Fatalf("remove %s error: %v", name, err)
+	}
+	assertStatus(util.StatusNotFound)
+}
+
+func TestGenericResources(t *testing.T) {
+	client := fake.NewSimpleClientset()
+
+	t.Run("kinds", func(t *testing.T) {
+		lifecycle[*corev1.Secret](t, client.CoreV1().Secrets(testNamespace),
+			&corev1.Secret{ObjectMeta: testMeta("secret", nil)})
+		lifecycle[*corev1.ServiceAccount](t, client.CoreV1().ServiceAccounts(testNamespace),
+			&corev1.ServiceAccount{ObjectMeta: testMeta("service-account", nil)})
+		lifecycle[*corev1.PersistentVolumeClaim](t, client.CoreV1().PersistentVolumeClaims(testNamespace),
+			&corev1.PersistentVolumeClaim{ObjectMeta: testMeta("pvc", nil)})
+		lifecycle[*batchv1.Job](t, client.BatchV1().Jobs(testNamespace),
+			&batchv1.Job{ObjectMeta: testMeta("job", nil)})
+		lifecycle[*batchv1.CronJob](t, client.BatchV1().CronJobs(testNamespace),
+			&batchv1.CronJob{ObjectMeta: testMeta("cron-job", nil)})
+		lifecycle[*networkingv1.Ingress](t, client.NetworkingV1().Ingresses(testNamespace),
+			&networkingv1.Ingress{ObjectMeta: testMeta("ingress", nil)})
+		lifecycle[*networkingv1.NetworkPolicy](t, client.NetworkingV1().NetworkPolicies(testNamespace),
+			&networkingv1.NetworkPolicy{ObjectMeta: testMeta("network-policy", nil)})
+	})
+
+	t.Run("update", func(t *testing.T) {
+		ctx := context.Background()
+		secrets := client.CoreV1().Secrets(testNamespace)
+		live := &corev1.Secret{ObjectMeta: testMeta("merged", map[string]string{"team": "graph", "tier": "old"})}
+		live.ResourceVersion = "7"
+		if _, err := secrets.Create(ctx, live, metav1.CreateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+		desired := &corev1.Secret{
+			ObjectMeta: testMeta("merged", map[string]string{"tier": "new"}),
+			StringData: map[string]string{"password": "nebula"},
+		}
+		if err := util.CreateOrUpdate[*corev1.Secret](ctx, secrets, desired, testLabel, false); err != nil {
+			t.Fatalf("update error: %v", err)
+		}
+		if desired.ResourceVersion != "" {
+			t.Errorf("expect elem is not mutated, but got resource version %s", desired.ResourceVersion)
+		}
+		updated, err := secrets.Get(ctx, "merged", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if updated.Labels["team"] != "graph" || updated.Labels["tier"] != "new" || updated.StringData["password"] != "nebula" {
+			t.Errorf("expect the labels are merged and the data is updated, but got %v %v", updated.Labels, updated.StringData)
+		}
+	})
+
+	t.Run("update pod", func(t *testing.T) {
+		ctx := context.Background()
+		pods := client.CoreV1().Pods(testNamespace)
+		pod := &corev1.Pod{
+			ObjectMeta: testMeta("console", nil),
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "console", Image: "vesoft/nebula-console"}}},
+		}
+		if err := util.CreateOrUpdate[*corev1.Pod](ctx, pods, pod, testLabel, false); err != nil {
+			t.Fatal(err)
+		}
+		scheduled, err := pods.Get(ctx, "console", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		scheduled.Spec.NodeName = "node-1"
+		if _, err = pods.Update(ctx, scheduled, metav1.UpdateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+		client.ClearActions()
+		if err = util.CreateOrUpdate[*corev1.Pod](ctx, pods, pod, testLabel, true); err != nil {
+			t.Fatalf("update pod error: %v", err)
+		}
+		for _, action := range client.Actions() {
+			if action.GetVerb() == "update" {
+				t.Errorf("expect the pod is applied instead of updated, but got %v", action)
+			}
+		}
+		applied, err := pods.Get(ctx, "console", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if applied.Spec.NodeName != "node-1" {
+			t.Errorf("expect the node name set by the scheduler is kept, but got %q", applied.Spec.NodeName)
+		}
+	})
+
+	t.Run("conflicted", func(t *testing.T) {
+		ctx := context.Background()
+		secrets := client.CoreV1().Secrets(testNamespace)
+		other := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "others", Namespace: testNamespace}}
+		if _, err := secrets.Create(ctx, other, metav1.CreateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+		err := util.CreateOrUpdate[*corev1.Secret](ctx, secrets, &corev1.Secret{ObjectMeta: testMeta("others", nil)}, testLabel, false)
+		if !errors.Is(err, util.ErrConflict) {
+			t.Errorf("expect a conflict, but got %v", err)
+		}
+		if err = util.RemoveResource[*corev1.Secret](ctx, secrets, "others", testLabel); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = secrets.Get(ctx, "others", metav1.GetOptions{}); err != nil {
+			t.Errorf("expect the resource of others is kept, but got %v", err)
+		}
+	})
+
+	t.Run("apply", func(t *testing.T) {
+		ctx := context.Background()
+		accounts := client.CoreV1().ServiceAccounts(testNamespace)
+		account := &corev1.ServiceAccount{ObjectMeta: testMeta("applied", nil)}
+		if err := util.CreateOrUpdate[*corev1.ServiceAccount](ctx, accounts, account, testLabel, false); err != nil {
+			t.Fatal(err)
+		}
+		account.Labels["tier"] = "applied"
+		result, err := util.Apply[*corev1.ServiceAccount](ctx, accounts, account, testLabel, false, false)
+		if err != nil {
+			t.Fatalf("apply error: %v", err)
+		}
+		if result.Labels["tier"] != "applied" {
+			t.Errorf("expect the applied label, but got %v", result.Labels)
+		}
+	})
+}